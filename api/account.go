@@ -1,14 +1,13 @@
 package api
 
 import (
-	"database/sql"
 	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	db "github.com/superjantung/bankita-api/db/sqlc"
 	"github.com/superjantung/bankita-api/token"
+	"github.com/superjantung/bankita-api/util"
 )
 
 type createAccountRequest struct {
@@ -18,7 +17,7 @@ type createAccountRequest struct {
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
@@ -31,14 +30,11 @@ func (server *Server) createAccount(ctx *gin.Context) {
 
 	account, err := server.store.CreateAccount(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
+		if errors.Is(err, db.ErrForeignKeyViolation) || errors.Is(err, db.ErrUniqueViolation) {
+			ctx.JSON(http.StatusForbidden, errorResponse(ctx, err))
+			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
 		return
 	}
 
@@ -52,25 +48,25 @@ type getAccountRequest struct {
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
 	account, err := server.store.GetAccount(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(ctx, err))
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if account.Owner != authPayload.Username {
 		err = errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ctx, err))
 		return
 	}
 
@@ -85,7 +81,7 @@ type listAccountRequest struct {
 func (server *Server) listAccount(ctx *gin.Context) {
 	var req listAccountRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
@@ -98,7 +94,65 @@ func (server *Server) listAccount(ctx *gin.Context) {
 
 	account, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+type updateOverdraftLimitRequest struct {
+	// OverdraftLimit is how far below zero the balance may go, in minor
+	// units. 0 disables the overdraft.
+	OverdraftLimit *int64 `json:"overdraft_limit" binding:"required,min=0"`
+}
+
+// updateOverdraftLimit sets how far below zero an account may go. Granting
+// credit is a bank decision, so only operators can change it.
+func (server *Server) updateOverdraftLimit(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	var req updateOverdraftLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	if user.Role != util.OperatorRole {
+		err := errors.New("only operators can change overdraft limits")
+		ctx.JSON(http.StatusForbidden, errorResponse(ctx, err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	if account.Owner == db.SettlementAccountOwner {
+		err := errors.New("settlement accounts have no overdraft limit")
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	account, err = server.store.UpdateAccountOverdraftLimit(ctx, db.UpdateAccountOverdraftLimitParams{
+		ID:             uri.ID,
+		OverdraftLimit: *req.OverdraftLimit,
+	})
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
 		return
 	}
 
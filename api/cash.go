@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/token"
+	"github.com/superjantung/bankita-api/util"
+)
+
+type cashRequest struct {
+	AccountID int64  `json:"account_id" binding:"required,min=1"`
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+	Currency  string `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createDeposit(ctx *gin.Context) {
+	var req cashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	if !server.validCashAccount(ctx, req) {
+		return
+	}
+
+	arg := db.DepositTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+	}
+
+	result, err := server.store.DepositTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	var req cashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	if !server.validCashAccount(ctx, req) {
+		return
+	}
+
+	arg := db.WithdrawTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+	}
+
+	result, err := server.store.WithdrawTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// validCashAccount checks a deposit or withdrawal. Cash is handed over at the
+// counter, so only operators can book it, on any customer account.
+func (server *Server) validCashAccount(ctx *gin.Context, req cashRequest) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return false
+	}
+
+	if user.Role != util.OperatorRole {
+		err := errors.New("only operators can deposit and withdraw cash")
+		ctx.JSON(http.StatusForbidden, errorResponse(ctx, err))
+		return false
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountID, req.Currency)
+	if !valid {
+		return false
+	}
+
+	if account.Owner == db.SettlementAccountOwner {
+		err := errors.New("cash cannot be deposited to or withdrawn from a settlement account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return false
+	}
+	return true
+}
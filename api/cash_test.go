@@ -0,0 +1,222 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/token"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func TestCashAPI(t *testing.T) {
+	amount := int64(10)
+
+	operator, _ := randomUser(t)
+	operator.Role = util.OperatorRole
+	user, _ := randomUser(t)
+	user.Role = util.DepositorRole
+	account := randomAccount(user.Username)
+	settlement := randomAccount(db.SettlementAccountOwner)
+	settlement.Currency = account.Currency
+
+	testCases := []struct {
+		name          string
+		url           string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "DepositOK",
+			url:  "/api/deposits",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.DepositTxParams{
+					AccountID: account.ID,
+					Amount:    amount,
+				}
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DepositNotOperator",
+			url:  "/api/deposits",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "DepositToSettlementAccount",
+			url:  "/api/deposits",
+			body: gin.H{
+				"account_id": settlement.ID,
+				"amount":     amount,
+				"currency":   settlement.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(settlement.ID)).Times(1).Return(settlement, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DepositSettlementAccountNotFound",
+			url:  "/api/deposits",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DepositTxResult{}, fmt.Errorf("transaction failed: %w", db.ErrSettlementAccountNotFound))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "WithdrawOK",
+			url:  "/api/withdrawals",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.WithdrawTxParams{
+					AccountID: account.ID,
+					Amount:    amount,
+				}
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WithdrawInsufficientFunds",
+			url:  "/api/withdrawals",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.WithdrawTxResult{}, fmt.Errorf("transaction failed: %w", db.ErrInsufficientFunds))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeAmount",
+			url:  "/api/withdrawals",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     -amount,
+				"currency":   account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubAuthSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
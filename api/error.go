@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
+	"github.com/superjantung/bankita-api/util"
+)
+
+// storeErrorStatus maps an error returned by the store to an HTTP status.
+func storeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, util.ErrCurrencyMismatch), errors.Is(err, util.ErrAmountOverflow):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrUniqueViolation), errors.Is(err, db.ErrForeignKeyViolation):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrSettlementAccountNotFound),
+		errors.Is(err, db.ErrIdempotencyKeyMismatch), errors.Is(err, db.ErrQuoteExpired),
+		errors.Is(err, db.ErrQuoteUsed), errors.Is(err, db.ErrQuoteMismatch):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, db.ErrSerialization):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// exchangeErrorStatus maps an error from a currency conversion to an HTTP status.
+func exchangeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, fx.ErrRateNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, fx.ErrAmountTooSmall):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
+	"github.com/superjantung/bankita-api/token"
+)
+
+type createFxQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+	// Amount is either an integer in minor units or a decimal string in
+	// major units of FromCurrency, such as "12.50".
+	Amount amountParam `json:"amount"`
+}
+
+func (server *Server) createFxQuote(ctx *gin.Context) {
+	var req createFxQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	amount, err := req.Amount.money(req.FromCurrency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	conversion, err := fx.Convert(ctx, server.rateProvider, req.FromCurrency, req.ToCurrency, amount.Amount)
+	if err != nil {
+		ctx.JSON(exchangeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     authPayload.Username,
+		FromCurrency: conversion.FromCurrency,
+		ToCurrency:   conversion.ToCurrency,
+		Amount:       conversion.Amount,
+		ToAmount:     conversion.ToAmount,
+		ExchangeRate: conversion.Rate,
+		Spread:       conversion.Spread,
+		ExpiresAt:    time.Now().Add(server.config.FXQuoteTTL),
+	}
+
+	quote, err := server.store.CreateFxQuote(ctx, arg)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
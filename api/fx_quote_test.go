@@ -0,0 +1,216 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/token"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func TestCreateFxQuoteAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	amount := int64(10)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.IDR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, int64(153450), arg.ToAmount)
+						require.Equal(t, "15345.000000000000", arg.ExchangeRate)
+						require.Equal(t, "0.010000", arg.Spread)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+
+						return db.FxQuote{
+							ID:           arg.ID,
+							Username:     arg.Username,
+							FromCurrency: arg.FromCurrency,
+							ToCurrency:   arg.ToCurrency,
+							Amount:       arg.Amount,
+							ToAmount:     arg.ToAmount,
+							ExchangeRate: arg.ExchangeRate,
+							Spread:       arg.Spread,
+							ExpiresAt:    arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var quote db.FxQuote
+				err = json.Unmarshal(data, &quote)
+				require.NoError(t, err)
+				require.NotZero(t, quote.ID)
+				require.Equal(t, amount, quote.Amount)
+				require.Equal(t, int64(153450), quote.ToAmount)
+			},
+		},
+		{
+			name: "DecimalAmount",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.IDR,
+				"amount":        "0.10",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, amount, arg.Amount)
+						require.Equal(t, int64(153450), arg.ToAmount)
+						return db.FxQuote{ID: arg.ID, Amount: arg.Amount, ToAmount: arg.ToAmount}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.IDR,
+				"amount":        "0.105",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.IDR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SameCurrency",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.USD,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ConvertedAmountTooSmall",
+			body: gin.H{
+				"from_currency": util.IDR,
+				"to_currency":   util.USD,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.IDR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(db.FxQuote{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubAuthSession(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/fx/quotes", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/superjantung/bankita-api/token"
+)
+
+// loggerMiddleware logs every request with the same fields as the gRPC and
+// gateway loggers.
+func loggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		startTime := time.Now()
+		ctx.Next()
+		duration := time.Since(startTime)
+
+		logger := log.Ctx(ctx.Request.Context()).Info()
+		if len(ctx.Errors) > 0 || ctx.Writer.Status() >= 500 {
+			logger = log.Ctx(ctx.Request.Context()).Error().Str("errors", ctx.Errors.String())
+		}
+
+		var username string
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			username = payload.(*token.Payload).Username
+		}
+
+		logger.Str("protocol", "http").
+			Str("method", ctx.Request.Method).
+			Str("path", ctx.Request.URL.Path).
+			Int("status_code", ctx.Writer.Status()).
+			Dur("duration", duration).
+			Str("username", username).
+			Msg("received a Gin request")
+	}
+}
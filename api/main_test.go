@@ -13,8 +13,13 @@ import (
 
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		IdempotencyKeyTTL:    time.Hour,
+		FXRatesFile:          "../fx/testdata/rates.json",
+		FXSpread:             "0.01",
+		FXQuoteTTL:           time.Minute,
 	}
 
 	server, err := NewServer(config, store)
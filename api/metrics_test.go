@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	server := newTestServer(t, nil)
+	counter := httpRequestsTotal.WithLabelValues(http.MethodGet, "/api/accounts/:id", "401")
+	before := testutil.ToFloat64(counter)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/api/accounts/1", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Equal(t, before+1, testutil.ToFloat64(counter))
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, strings.Contains(recorder.Body.String(), "bankita_http_requests_total"))
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
 	"github.com/superjantung/bankita-api/token"
 )
 
@@ -16,33 +18,58 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// authMiddleware accepts a bearer access token whose session is still active.
+// Refresh tokens are rejected, and so are access tokens once their session is
+// blocked by a logout or a revocation.
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
 			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, err))
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
 			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, err))
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
 			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, err))
 			return
 		}
 
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		payload, err := tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, err))
+			return
+		}
+
+		if payload.SessionID == uuid.Nil {
+			err := errors.New("access token has no session")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, err))
+			return
+		}
+
+		session, err := store.GetSession(ctx, payload.SessionID)
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(ctx, err))
+			return
+		}
+
+		if session.IsBlocked {
+			err := errors.New("blocked session")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, err))
 			return
 		}
 
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/superjantung/bankita-api/util"
+)
+
+const requestIDKey = "request_id"
+
+// requestIDMiddleware accepts the client X-Request-ID or generates one, echoes
+// it in the response and attaches it to the request logger.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := util.RequestID(ctx.GetHeader(util.RequestIDHeader))
+
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(util.RequestIDHeader, requestID)
+
+		logger := log.With().Str("request_id", requestID).Logger()
+		ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context()))
+
+		ctx.Next()
+	}
+}
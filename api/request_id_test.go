@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestID string
+		check     func(t *testing.T, requestID string)
+	}{
+		{
+			name:      "Accepted",
+			requestID: "client-request-id",
+			check: func(t *testing.T, requestID string) {
+				require.Equal(t, "client-request-id", requestID)
+			},
+		},
+		{
+			name:      "Generated",
+			requestID: "",
+			check: func(t *testing.T, requestID string) {
+				require.NotEmpty(t, requestID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/accounts/1", nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(util.RequestIDHeader, tc.requestID)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+			requestID := recorder.Header().Get(util.RequestIDHeader)
+			tc.check(t, requestID)
+
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			require.Equal(t, requestID, body["request_id"])
+		})
+	}
+}
@@ -2,20 +2,24 @@ package api
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
 	"github.com/superjantung/bankita-api/token"
 	"github.com/superjantung/bankita-api/util"
 )
 
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config       util.Config
+	store        db.Store
+	tokenMaker   token.Maker
+	rateProvider fx.RateProvider
+	router       *gin.Engine
 }
 
 func NewServer(config util.Config, store db.Store) (*Server, error) {
@@ -24,10 +28,16 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		rateProvider: rateProvider,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -39,7 +49,10 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 }
 
 func (server *Server) setupRouter() {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery(), requestIDMiddleware(), loggerMiddleware(), metricsMiddleware())
+
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Public routes
 	router.POST("/api/users", server.createUser)
@@ -47,12 +60,21 @@ func (server *Server) setupRouter() {
 	router.POST("/api/tokens/renew_access", server.renewAccessToken)
 
 	// Authenticated routes
-	authRoutes := router.Group("/api").Use(authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/api").Use(authMiddleware(server.tokenMaker, server.store))
 	{
 		authRoutes.POST("/accounts", server.createAccount)
 		authRoutes.GET("/accounts/:id", server.getAccount)
 		authRoutes.GET("/accounts", server.listAccount)
+		authRoutes.GET("/accounts/:id/statement", server.getStatement)
+		authRoutes.PATCH("/accounts/:id/overdraft_limit", server.updateOverdraftLimit)
 		authRoutes.POST("/transfers", server.createTransfer)
+		authRoutes.POST("/fx/quotes", server.createFxQuote)
+		authRoutes.POST("/deposits", server.createDeposit)
+		authRoutes.POST("/withdrawals", server.createWithdrawal)
+		authRoutes.POST("/users/logout", server.logoutUser)
+		authRoutes.GET("/sessions", server.listSessions)
+		authRoutes.DELETE("/sessions/:id", server.revokeSession)
+		authRoutes.DELETE("/sessions", server.revokeAllSessions)
 	}
 
 	server.router = router
@@ -62,6 +84,14 @@ func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// Handler exposes the router so the caller can manage the HTTP server.
+func (server *Server) Handler() http.Handler {
+	return server.router
+}
+
+func errorResponse(ctx *gin.Context, err error) gin.H {
+	return gin.H{
+		"error":      err.Error(),
+		"request_id": ctx.GetString(requestIDKey),
+	}
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/token"
+)
+
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+func (server *Server) listSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	sessions, err := server.store.ListActiveSessions(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	rsp := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		rsp[i] = newSessionResponse(session)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken, token.TokenTypeRefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ctx, err))
+		return
+	}
+
+	session, valid := server.validSession(ctx, refreshPayload.ID)
+	if !valid {
+		return
+	}
+
+	if session.RefreshToken != req.RefreshToken {
+		err := errors.New("mismatched session token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ctx, err))
+		return
+	}
+
+	err = server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type revokeSessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+func (server *Server) revokeSession(ctx *gin.Context) {
+	var req revokeSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	session, valid := server.validSession(ctx, uuid.MustParse(req.ID))
+	if !valid {
+		return
+	}
+
+	err := server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (server *Server) revokeAllSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	err := server.store.BlockUserSessions(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// validSession loads the session and makes sure it belongs to the
+// authenticated user, writing the error response when it does not.
+func (server *Server) validSession(ctx *gin.Context, sessionID uuid.UUID) (db.Session, bool) {
+	session, err := server.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(ctx, err))
+			return session, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return session, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if session.Username != authPayload.Username {
+		err := errors.New("session does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ctx, err))
+		return session, false
+	}
+
+	return session, true
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/token"
+)
+
+const maxStatementPeriod = 366 * 24 * time.Hour
+
+type getStatementURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getStatementQuery struct {
+	From time.Time `form:"from" binding:"required"`
+	To   time.Time `form:"to" binding:"required,gtfield=From"`
+}
+
+func (server *Server) getStatement(ctx *gin.Context) {
+	var uri getStatementURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	var query getStatementQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+	if query.To.Sub(query.From) > maxStatementPeriod {
+		err := fmt.Errorf("statement period must be at most %s", maxStatementPeriod)
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err = errors.New("account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ctx, err))
+		return
+	}
+
+	statement, err := server.store.StatementTx(ctx, db.StatementTxParams{
+		AccountID: uri.ID,
+		From:      query.From,
+		To:        query.To,
+	})
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statement)
+}
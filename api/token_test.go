@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/token"
+)
+
+func randomSession(t *testing.T, tokenMaker token.Maker, username string) (session db.Session, refreshToken string) {
+	refreshToken, payload, err := tokenMaker.CreateRefreshToken(username, time.Hour)
+	require.NoError(t, err)
+
+	session = db.Session{
+		ID:           payload.ID,
+		FamilyID:     payload.ID,
+		Username:     username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+	return
+}
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+						require.Equal(t, session.ID, arg.SessionID)
+						require.NotEqual(t, session.RefreshToken, arg.NewSession.RefreshToken)
+						return db.RotateSessionTxResult{
+							OldSession: session,
+							NewSession: db.Session{ID: arg.NewSession.ID, FamilyID: session.FamilyID},
+						}, nil
+					})
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp renewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.NotEqual(t, session.RefreshToken, rsp.RefreshToken)
+				require.NotEqual(t, session.ID, rsp.SessionID)
+			},
+		},
+		{
+			name: "ReusedRefreshToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsRotated = true
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentRotation",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateSessionTxResult{}, fmt.Errorf("transaction failed: %w", db.ErrSessionRotated))
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubAuthSession(store)
+			server := newTestServer(t, store)
+
+			session, refreshToken := randomSession(t, server.tokenMaker, user.Username)
+			tc.buildStubs(store, session)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			url := "/api/tokens/renew_access"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, session)
+		})
+	}
+}
@@ -1,56 +1,175 @@
 package api
 
 import (
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
 	"github.com/superjantung/bankita-api/token"
+	"github.com/superjantung/bankita-api/util"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
 )
 
 type transferRequest struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	// Amount is either an integer in minor units or a decimal string in
+	// major units of Currency, such as "12.50".
+	Amount amountParam `json:"amount"`
+	// ToCurrency converts the amount when it differs from Currency.
+	ToCurrency string `json:"to_currency" binding:"omitempty,currency"`
+	// QuoteID executes the transfer at the rate of a quote from /fx/quotes.
+	QuoteID string `json:"quote_id" binding:"omitempty,uuid"`
+}
+
+// amountParam is an amount in a request body. A JSON number is read as minor
+// units, as clients have always sent it, and a JSON string as a decimal in
+// major units.
+type amountParam struct {
+	minorUnits int64
+	decimal    string
+}
+
+func (param *amountParam) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &param.decimal)
+	}
+	return json.Unmarshal(data, &param.minorUnits)
+}
+
+func (param amountParam) money(currency string) (util.Money, error) {
+	money := util.NewMoney(param.minorUnits, currency)
+	if param.decimal != "" {
+		var err error
+		money, err = util.ParseMoney(param.decimal, currency)
+		if err != nil {
+			return money, err
+		}
+	}
+
+	if !money.IsPositive() {
+		return money, errors.New("amount must be greater than 0")
+	}
+	return money, nil
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	amount, err := req.Amount.money(req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        amount,
+	}
+
+	if req.QuoteID != "" {
+		quoteID := uuid.MustParse(req.QuoteID)
+		arg.QuoteID = &quoteID
+	}
+
+	if key := ctx.GetHeader(idempotencyKeyHeader); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			err := fmt.Errorf("idempotency key must be at most %d characters", maxIdempotencyKeyLength)
+			ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+			return
+		}
+
+		arg.Idempotency = &db.IdempotencyParams{
+			Key:       key,
+			Username:  authPayload.Username,
+			ExpiresAt: time.Now().Add(server.config.IdempotencyKeyTTL),
+		}
+	}
+
+	toCurrency := req.ToCurrency
+	if toCurrency == "" && arg.QuoteID == nil {
+		toCurrency = req.Currency
+	}
+
+	// A retry is answered from the recorded result, even if the quote has
+	// been used up or the exchange rate has moved since.
+	if arg.Idempotency != nil {
+		result, replayed, err := server.store.ReplayTransferTx(ctx, arg, toCurrency)
+		if err != nil {
+			ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+			return
+		}
+		if replayed {
+			ctx.JSON(http.StatusOK, result)
+			return
+		}
+	}
+
 	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if fromAccount.Owner != authPayload.Username {
 		err := errors.New("from account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ctx, err))
 		return
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if arg.QuoteID != nil {
+		quote, valid := server.validQuote(ctx, *arg.QuoteID, authPayload.Username)
+		if !valid {
+			return
+		}
+
+		if toCurrency == "" {
+			toCurrency = quote.ToCurrency
+		}
+		if quote.FromCurrency != req.Currency || quote.ToCurrency != toCurrency || quote.Amount != amount.Amount {
+			ctx.JSON(http.StatusBadRequest, errorResponse(ctx, db.ErrQuoteMismatch))
+			return
+		}
+	}
+
+	_, valid = server.validAccount(ctx, req.ToAccountID, toCurrency)
 	if !valid {
 		return
 	}
 
-	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+	if arg.QuoteID == nil && toCurrency != req.Currency {
+		conversion, err := fx.Convert(ctx, server.rateProvider, req.Currency, toCurrency, amount.Amount)
+		if err != nil {
+			ctx.JSON(exchangeErrorStatus(err), errorResponse(ctx, err))
+			return
+		}
+
+		arg.Exchange = &db.ExchangeParams{
+			ToAmount: util.NewMoney(conversion.ToAmount, toCurrency),
+			Rate:     conversion.Rate,
+			Spread:   conversion.Spread,
+		}
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
 		return
 	}
 
@@ -60,20 +179,36 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(ctx, err))
 			return account, false
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
 		return account, false
 	}
 
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return account, false
 	}
 
 	return account, true
 }
+
+func (server *Server) validQuote(ctx *gin.Context, quoteID uuid.UUID, username string) (db.FxQuote, bool) {
+	quote, err := server.store.GetFxQuote(ctx, quoteID)
+	if err != nil {
+		ctx.JSON(storeErrorStatus(err), errorResponse(ctx, err))
+		return quote, false
+	}
+
+	if quote.Username != username {
+		err := errors.New("exchange quote does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(ctx, err))
+		return quote, false
+	}
+
+	return quote, true
+}
@@ -0,0 +1,3 @@
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = '_settlement');
+DELETE FROM "accounts" WHERE "owner" = '_settlement';
+DELETE FROM "users" WHERE "username" = '_settlement';
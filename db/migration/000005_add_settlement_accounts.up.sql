@@ -0,0 +1,7 @@
+-- The settlement user owns one cash account per currency. Deposits and
+-- withdrawals are booked against it so every entry has a counterpart.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('_settlement', '', 'Bankita Settlement', 'settlement@bankita.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('_settlement', 0, 'USD'), ('_settlement', 0, 'IDR');
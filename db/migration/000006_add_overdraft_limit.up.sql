@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" BIGINT NOT NULL DEFAULT 0 CHECK ("overdraft_limit" >= 0);
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'How far below zero the balance may go';
@@ -0,0 +1,11 @@
+CREATE TABLE "idempotency_keys" (
+    "username" VARCHAR NOT NULL,
+    "idempotency_key" VARCHAR NOT NULL,
+    "request_hash" VARCHAR NOT NULL,
+    "response" JSONB NOT NULL DEFAULT '{}',
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("username", "idempotency_key")
+);
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
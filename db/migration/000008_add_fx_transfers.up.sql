@@ -0,0 +1,11 @@
+ALTER TABLE "transfers" ADD COLUMN "to_amount" BIGINT;
+UPDATE "transfers" SET "to_amount" = "amount";
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" NUMERIC(24, 12) NOT NULL DEFAULT 1 CHECK ("exchange_rate" > 0);
+ALTER TABLE "transfers" ADD COLUMN "spread" NUMERIC(8, 6) NOT NULL DEFAULT 0 CHECK ("spread" >= 0 AND "spread" < 1);
+
+COMMENT ON COLUMN "transfers"."amount" IS 'Positive amount debited from the source account, in its currency';
+COMMENT ON COLUMN "transfers"."to_amount" IS 'Positive amount credited to the target account, in its currency';
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'Applied rate after the spread, 1 for same-currency transfers';
+COMMENT ON COLUMN "transfers"."spread" IS 'Fraction taken off the mid-market rate';
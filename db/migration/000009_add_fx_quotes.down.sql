@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "quote_id";
+
+DROP TABLE IF EXISTS "fx_quotes";
@@ -0,0 +1,21 @@
+CREATE TABLE "fx_quotes" (
+    "id" uuid PRIMARY KEY,
+    "username" VARCHAR NOT NULL,
+    "from_currency" VARCHAR NOT NULL,
+    "to_currency" VARCHAR NOT NULL,
+    "amount" BIGINT NOT NULL CHECK ("amount" > 0),
+    "to_amount" BIGINT NOT NULL CHECK ("to_amount" > 0),
+    "exchange_rate" NUMERIC(24, 12) NOT NULL CHECK ("exchange_rate" > 0),
+    "spread" NUMERIC(8, 6) NOT NULL CHECK ("spread" >= 0 AND "spread" < 1),
+    "used_at" TIMESTAMPTZ,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "fx_quotes" ("username");
+
+ALTER TABLE "transfers" ADD COLUMN "quote_id" uuid UNIQUE REFERENCES "fx_quotes" ("id");
+
+COMMENT ON COLUMN "fx_quotes"."used_at" IS 'Set when a transfer executes the quote, a quote can be used once';
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_currency_fkey";
+
+DELETE FROM "accounts"
+WHERE "owner" = '_settlement' AND "currency" IN ('EUR', 'SGD')
+  AND NOT EXISTS (SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id");
+
+DROP TABLE IF EXISTS "currencies";
@@ -0,0 +1,22 @@
+CREATE TABLE "currencies" (
+    "code" VARCHAR(3) PRIMARY KEY CHECK ("code" ~ '^[A-Z]{3}$'),
+    "minor_unit" SMALLINT NOT NULL CHECK ("minor_unit" BETWEEN 0 AND 4),
+    "symbol" VARCHAR NOT NULL,
+    "enabled" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+COMMENT ON COLUMN "currencies"."minor_unit" IS 'Number of decimal places amounts in this currency are stored with';
+COMMENT ON COLUMN "currencies"."enabled" IS 'Whether new accounts, transfers and quotes may use the currency';
+
+INSERT INTO "currencies" ("code", "minor_unit", "symbol", "enabled")
+VALUES ('USD', 2, '$', TRUE), ('IDR', 2, 'Rp', TRUE), ('EUR', 2, '€', FALSE), ('SGD', 2, 'S$', FALSE);
+
+-- Deposits and withdrawals need a settlement account in every currency, so
+-- disabled currencies can later be enabled with a single update.
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('_settlement', 0, 'EUR'), ('_settlement', 0, 'SGD')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
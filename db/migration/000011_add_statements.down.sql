@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "unmatched_transfer_legs";
+
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "transfer_id";
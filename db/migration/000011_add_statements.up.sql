@@ -0,0 +1,33 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" BIGINT REFERENCES "transfers" ("id");
+
+-- Entries of a transfer are created in the same transaction as the transfer,
+-- so they share its created_at.
+UPDATE "entries" AS e
+SET "transfer_id" = t."id"
+FROM "transfers" AS t
+WHERE e."created_at" = t."created_at"
+  AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount"));
+
+-- Legs whose entry was not matched, e.g. because its created_at differs from
+-- the transfer's, keep a NULL transfer_id and show up on statements without a
+-- counterparty. They are recorded so they can be reviewed and fixed by hand.
+CREATE TABLE "unmatched_transfer_legs" (
+  "transfer_id" bigint PRIMARY KEY REFERENCES "transfers" ("id"),
+  "missing_legs" integer NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+INSERT INTO "unmatched_transfer_legs" ("transfer_id", "missing_legs")
+SELECT t."id", 2 - COUNT(e."id")
+FROM "transfers" AS t
+LEFT JOIN "entries" AS e ON e."transfer_id" = t."id"
+GROUP BY t."id"
+HAVING COUNT(e."id") < 2;
+
+COMMENT ON TABLE "unmatched_transfer_legs" IS 'Transfers whose entries the statement backfill could not link to them';
+
+CREATE INDEX ON "entries" ("transfer_id");
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'Transfer the entry belongs to, NULL for deposits, withdrawals and transfer entries the backfill could not match';
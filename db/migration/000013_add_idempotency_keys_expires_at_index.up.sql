@@ -0,0 +1 @@
+CREATE INDEX ON "idempotency_keys" ("expires_at");
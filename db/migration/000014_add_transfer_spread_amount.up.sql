@@ -0,0 +1,3 @@
+ALTER TABLE "transfers" ADD COLUMN "spread_amount" BIGINT NOT NULL DEFAULT 0 CHECK ("spread_amount" >= 0);
+
+COMMENT ON COLUMN "transfers"."spread_amount" IS 'Part of amount kept as the exchange spread, in the source currency. Cross-currency transfers created before this column was added have 0';
@@ -0,0 +1,8 @@
+// Package migration embeds the SQL schema migrations so the server can apply
+// them without the migrate CLI.
+package migration
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
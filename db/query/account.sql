@@ -7,6 +7,10 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
+-- name: GetAccountByOwnerCurrency :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
 WHERE owner = $1
@@ -28,6 +32,12 @@ SET balance = $2
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING *;
+
 -- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + sqlc.arg(amount)
@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +16,25 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at >= $2;
+
+-- name: ListStatementEntries :many
+SELECT
+  e.*,
+  SUM(e.amount) OVER (ORDER BY e.created_at, e.id)::bigint AS cumulative_amount,
+  c.id AS counterparty_account_id,
+  c.owner AS counterparty_owner
+FROM entries AS e
+LEFT JOIN transfers AS t ON t.id = e.transfer_id
+LEFT JOIN accounts AS c ON c.id = CASE
+    WHEN t.from_account_id = e.account_id THEN t.to_account_id
+    ELSE t.from_account_id
+  END
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
\ No newline at end of file
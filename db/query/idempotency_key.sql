@@ -0,0 +1,29 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  username,
+  idempotency_key,
+  request_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (username, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    response = '{}',
+    expires_at = EXCLUDED.expires_at,
+    created_at = now()
+WHERE idempotency_keys.expires_at <= now()
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND idempotency_key = $2 LIMIT 1;
+
+-- name: UpdateIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET response = $3
+WHERE username = $1 AND idempotency_key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at < now();
\ No newline at end of file
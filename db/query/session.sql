@@ -5,6 +5,7 @@ WHERE id = $1 LIMIT 1;
 -- name: CreateSession :one
 INSERT INTO sessions (
   id, 
+  family_id,
   username, 
   refresh_token,
   user_agent,
@@ -12,6 +13,30 @@ INSERT INTO sessions (
   is_blocked,
   expires_at
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: RotateSession :one
+UPDATE sessions
+SET is_rotated = TRUE
+WHERE id = $1 AND is_rotated = FALSE
+RETURNING *;
+
+-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE family_id = $1;
+
+-- name: ListActiveSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+  AND is_rotated = FALSE
+  AND is_blocked = FALSE
+  AND expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: BlockUserSessions :exec
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE username = $1 AND is_blocked = FALSE;
\ No newline at end of file
@@ -2,9 +2,14 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  exchange_rate,
+  spread,
+  spread_amount,
+  quote_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -18,4 +23,8 @@ WHERE
     to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: ListUnmatchedTransferLegs :many
+SELECT * FROM unmatched_transfer_legs
+ORDER BY transfer_id;
\ No newline at end of file
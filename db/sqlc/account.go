@@ -0,0 +1,7 @@
+package db
+
+// HasSufficientFunds reports whether the balance is still within the
+// account's overdraft limit.
+func (account Account) HasSufficientFunds() bool {
+	return account.Balance >= -account.OverdraftLimit
+}
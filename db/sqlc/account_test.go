@@ -2,10 +2,10 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 	"github.com/superjantung/bankita-api/util"
 )
@@ -27,11 +27,15 @@ func assertAccountEqual(t *testing.T, expected, actual Account) {
 }
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountInCurrency(t, util.RandomCurrencies())
+}
+
+func createRandomAccountInCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomBalance(),
-		Currency: util.RandomCurrencies(),
+		Currency: currency,
 	}
 
 	createdAccount, err := testQueries.CreateAccount(context.Background(), arg)
@@ -42,6 +46,17 @@ func createRandomAccount(t *testing.T) Account {
 	return createdAccount
 }
 
+// fundAccount tops up the account balance so that it can cover transfers of
+// the given total amount.
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	fundedAccount, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+	return fundedAccount
+}
+
 func TestCreateAccount(t *testing.T) {
 	createRandomAccount(t)
 }
@@ -88,8 +103,8 @@ func TestDeleteAccount(t *testing.T) {
 	deletedAccount, err := testQueries.GetAccount(context.Background(), createdAccount.ID)
 	require.Error(t, err)
 
-	// Assert that the error is of type sql.ErrNoRows
-	require.Equal(t, sql.ErrNoRows, err)
+	// Assert that the error is of type pgx.ErrNoRows
+	require.Equal(t, pgx.ErrNoRows, err)
 
 	// Assert that the deleted account is empty
 	require.Empty(t, deletedAccount)
@@ -0,0 +1,41 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: currency.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listCurrencies = `-- name: ListCurrencies :many
+SELECT code, minor_unit, symbol, enabled, created_at FROM currencies
+ORDER BY code
+`
+
+func (q *Queries) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	rows, err := q.db.Query(ctx, listCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Currency{}
+	for rows.Next() {
+		var i Currency
+		if err := rows.Scan(
+			&i.Code,
+			&i.MinorUnit,
+			&i.Symbol,
+			&i.Enabled,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
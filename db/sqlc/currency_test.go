@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	enabled := make(map[string]bool)
+	for i, currency := range currencies {
+		if i > 0 {
+			require.Less(t, currencies[i-1].Code, currency.Code)
+		}
+		enabled[currency.Code] = currency.Enabled
+	}
+
+	require.True(t, enabled[util.USD])
+	require.True(t, enabled[util.IDR])
+}
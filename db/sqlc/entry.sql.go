@@ -7,53 +7,60 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3
+) RETURNING id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64       `json:"account_id"`
+	Amount     int64       `json:"amount"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, getEntry, id)
+	row := q.db.QueryRow(ctx, getEntry, id)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -67,7 +74,7 @@ type ListEntriesParams struct {
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -80,16 +87,95 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT
+  e.id, e.account_id, e.amount, e.created_at, e.transfer_id,
+  SUM(e.amount) OVER (ORDER BY e.created_at, e.id)::bigint AS cumulative_amount,
+  c.id AS counterparty_account_id,
+  c.owner AS counterparty_owner
+FROM entries AS e
+LEFT JOIN transfers AS t ON t.id = e.transfer_id
+LEFT JOIN accounts AS c ON c.id = CASE
+    WHEN t.from_account_id = e.account_id THEN t.to_account_id
+    ELSE t.from_account_id
+  END
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                    int64       `json:"id"`
+	AccountID             int64       `json:"account_id"`
+	Amount                int64       `json:"amount"`
+	CreatedAt             time.Time   `json:"created_at"`
+	TransferID            pgtype.Int8 `json:"transfer_id"`
+	CumulativeAmount      int64       `json:"cumulative_amount"`
+	CounterpartyAccountID pgtype.Int8 `json:"counterparty_account_id"`
+	CounterpartyOwner     pgtype.Text `json:"counterparty_owner"`
+}
+
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.CumulativeAmount,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyOwner,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
+
+const sumEntriesSince = `-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at >= $2
+`
+
+type SumEntriesSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumEntriesSince, arg.AccountID, arg.CreatedAt)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
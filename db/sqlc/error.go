@@ -0,0 +1,76 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes translated by translateError.
+const (
+	UniqueViolation      = "23505"
+	ForeignKeyViolation  = "23503"
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
+)
+
+// Errors returned by the Store. Driver errors are translated into these so
+// callers never have to inspect driver specific error types.
+var (
+	ErrRecordNotFound      = errors.New("record not found")
+	ErrUniqueViolation     = errors.New("unique constraint violation")
+	ErrForeignKeyViolation = errors.New("foreign key constraint violation")
+	ErrSerialization       = errors.New("could not serialize transaction")
+
+	ErrInsufficientFunds         = errors.New("insufficient funds")
+	ErrSettlementAccountNotFound = errors.New("settlement account not found")
+	ErrIdempotencyKeyMismatch    = errors.New("idempotency key was already used for a different request")
+	ErrQuoteExpired              = errors.New("exchange quote has expired")
+	ErrQuoteUsed                 = errors.New("exchange quote has already been used")
+	ErrQuoteMismatch             = errors.New("transfer does not match the exchange quote")
+)
+
+// Error is a driver error translated into one of the Store errors. It matches
+// its Kind with errors.Is and still unwraps to the original driver error.
+type Error struct {
+	Kind       error
+	Constraint string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Kind: ErrRecordNotFound, Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case UniqueViolation:
+			return &Error{Kind: ErrUniqueViolation, Constraint: pgErr.ConstraintName, Err: err}
+		case ForeignKeyViolation:
+			return &Error{Kind: ErrForeignKeyViolation, Constraint: pgErr.ConstraintName, Err: err}
+		case SerializationFailure, DeadlockDetected:
+			return &Error{Kind: ErrSerialization, Err: err}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{name: "NoRows", err: pgx.ErrNoRows, kind: ErrRecordNotFound},
+		{name: "UniqueViolation", err: &pgconn.PgError{Code: UniqueViolation}, kind: ErrUniqueViolation},
+		{name: "ForeignKeyViolation", err: &pgconn.PgError{Code: ForeignKeyViolation}, kind: ErrForeignKeyViolation},
+		{name: "SerializationFailure", err: &pgconn.PgError{Code: SerializationFailure}, kind: ErrSerialization},
+		{name: "DeadlockDetected", err: &pgconn.PgError{Code: DeadlockDetected}, kind: ErrSerialization},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := translateError(tc.err)
+			require.ErrorIs(t, err, tc.kind)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	require.NoError(t, translateError(nil))
+
+	other := errors.New("other")
+	require.Equal(t, other, translateError(other))
+}
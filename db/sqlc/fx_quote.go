@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/superjantung/bankita-api/util"
+)
+
+// useFxQuote locks the quote, checks that it can still execute a transfer of
+// amount and marks it used. The returned exchange is the one locked in when
+// the quote was created.
+func useFxQuote(ctx context.Context, q *Queries, id uuid.UUID, amount util.Money) (ExchangeParams, error) {
+	quote, err := q.GetFxQuoteForUpdate(ctx, id)
+	if err != nil {
+		return ExchangeParams{}, err
+	}
+
+	switch {
+	case quote.UsedAt.Valid:
+		return ExchangeParams{}, ErrQuoteUsed
+	case !time.Now().Before(quote.ExpiresAt):
+		return ExchangeParams{}, ErrQuoteExpired
+	case quote.Amount != amount.Amount || quote.FromCurrency != amount.Currency:
+		return ExchangeParams{}, ErrQuoteMismatch
+	}
+
+	err = q.MarkFxQuoteUsed(ctx, id)
+	if err != nil {
+		return ExchangeParams{}, err
+	}
+
+	return ExchangeParams{
+		ToAmount: util.NewMoney(quote.ToAmount, quote.ToCurrency),
+		Rate:     quote.ExchangeRate,
+		Spread:   quote.Spread,
+	}, nil
+}
@@ -0,0 +1,130 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: fx_quote.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFxQuote = `-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (
+  id,
+  username,
+  from_currency,
+  to_currency,
+  amount,
+  to_amount,
+  exchange_rate,
+  spread,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, username, from_currency, to_currency, amount, to_amount, exchange_rate, spread, used_at, expires_at, created_at
+`
+
+type CreateFxQuoteParams struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Amount       int64     `json:"amount"`
+	ToAmount     int64     `json:"to_amount"`
+	ExchangeRate string    `json:"exchange_rate"`
+	Spread       string    `json:"spread"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error) {
+	row := q.db.QueryRow(ctx, createFxQuote,
+		arg.ID,
+		arg.Username,
+		arg.FromCurrency,
+		arg.ToCurrency,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.Spread,
+		arg.ExpiresAt,
+	)
+	var i FxQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Amount,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Spread,
+		&i.UsedAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFxQuote = `-- name: GetFxQuote :one
+SELECT id, username, from_currency, to_currency, amount, to_amount, exchange_rate, spread, used_at, expires_at, created_at FROM fx_quotes
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetFxQuote(ctx context.Context, id uuid.UUID) (FxQuote, error) {
+	row := q.db.QueryRow(ctx, getFxQuote, id)
+	var i FxQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Amount,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Spread,
+		&i.UsedAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFxQuoteForUpdate = `-- name: GetFxQuoteForUpdate :one
+SELECT id, username, from_currency, to_currency, amount, to_amount, exchange_rate, spread, used_at, expires_at, created_at FROM fx_quotes
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetFxQuoteForUpdate(ctx context.Context, id uuid.UUID) (FxQuote, error) {
+	row := q.db.QueryRow(ctx, getFxQuoteForUpdate, id)
+	var i FxQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Amount,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Spread,
+		&i.UsedAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const markFxQuoteUsed = `-- name: MarkFxQuoteUsed :exec
+UPDATE fx_quotes
+SET used_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) MarkFxQuoteUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markFxQuoteUsed, id)
+	return err
+}
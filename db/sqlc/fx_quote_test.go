@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func createRandomFxQuote(t *testing.T, username string, amount int64, expiresAt time.Time) FxQuote {
+	arg := CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     username,
+		FromCurrency: util.USD,
+		ToCurrency:   util.IDR,
+		Amount:       amount,
+		ToAmount:     amount * 15345,
+		ExchangeRate: "15345.000000000000",
+		Spread:       "0.010000",
+		ExpiresAt:    expiresAt,
+	}
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.ID, quote.ID)
+	require.Equal(t, arg.Username, quote.Username)
+	require.Equal(t, arg.Amount, quote.Amount)
+	require.Equal(t, arg.ToAmount, quote.ToAmount)
+	require.Equal(t, arg.ExchangeRate, quote.ExchangeRate)
+	require.Equal(t, arg.Spread, quote.Spread)
+	require.False(t, quote.UsedAt.Valid)
+	require.WithinDuration(t, arg.ExpiresAt, quote.ExpiresAt, time.Second)
+	return quote
+}
+
+func TestGetFxQuote(t *testing.T) {
+	user := createRandomUser(t)
+	quote := createRandomFxQuote(t, user.Username, util.RandomBalance()+1, time.Now().Add(time.Minute))
+
+	retrievedQuote, err := testQueries.GetFxQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.Equal(t, quote.ID, retrievedQuote.ID)
+	require.Equal(t, quote.ToAmount, retrievedQuote.ToAmount)
+	require.Equal(t, quote.ExchangeRate, retrievedQuote.ExchangeRate)
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IdempotencyParams identifies a client request that must be executed at
+// most once. Keys are scoped to the user and stop being honored at ExpiresAt.
+type IdempotencyParams struct {
+	Key       string
+	Username  string
+	ExpiresAt time.Time
+}
+
+// claimIdempotencyKey records the key for a new request. If the key was
+// already used for the same request, the stored response is decoded into
+// replay and true is returned so the caller can skip the work. A concurrent
+// request with the same key blocks on the insert until the first one commits.
+func claimIdempotencyKey(ctx context.Context, q *Queries, arg IdempotencyParams, request interface{}, replay interface{}) (bool, error) {
+	requestHash, err := hashRequest(request)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.Key,
+		RequestHash:    requestHash,
+		ExpiresAt:      arg.ExpiresAt,
+	})
+	if err == nil {
+		return false, nil
+	}
+	if err != pgx.ErrNoRows {
+		return false, err
+	}
+
+	// The insert did nothing, so a live row with this key already exists.
+	existing, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.Key,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, replayIdempotentResponse(existing, requestHash, replay)
+}
+
+// replayIdempotencyKey decodes the response stored for the key into replay
+// and returns true if the same request was already completed with it. Unlike
+// claimIdempotencyKey it does not record the key, so it can be called before
+// any work is done and outside of a transaction.
+func replayIdempotencyKey(ctx context.Context, q *Queries, arg IdempotencyParams, request interface{}, replay interface{}) (bool, error) {
+	requestHash, err := hashRequest(request)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.Key,
+	})
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// An expired key is claimed afresh by the transaction.
+	if !existing.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+
+	return true, replayIdempotentResponse(existing, requestHash, replay)
+}
+
+func replayIdempotentResponse(existing IdempotencyKey, requestHash string, replay interface{}) error {
+	if existing.RequestHash != requestHash {
+		return ErrIdempotencyKeyMismatch
+	}
+	return json.Unmarshal(existing.Response, replay)
+}
+
+// saveIdempotentResponse stores the result returned for the claimed key.
+func saveIdempotentResponse(ctx context.Context, q *Queries, arg IdempotencyParams, response interface{}) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	return q.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.Key,
+		Response:       data,
+	})
+}
+
+func hashRequest(request interface{}) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	transfersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bankita_transfers_total",
+		Help: "Number of TransferTx calls by currency and result.",
+	}, []string{"currency", "status"})
+
+	txRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bankita_db_tx_retries_total",
+		Help: "Number of transactions retried, by PostgreSQL error code.",
+	}, []string{"code"})
+
+	txRetriesExhaustedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bankita_db_tx_retries_exhausted_total",
+		Help: "Number of transactions that still failed after the last retry.",
+	})
+)
+
+func observeTransfer(currency string, err error) {
+	if currency == "" {
+		currency = "unknown"
+	}
+
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	transfersTotal.WithLabelValues(currency, status).Inc()
+}
+
+// PoolCollector exports the connection pool statistics of pgxpool.
+type PoolCollector struct {
+	connPool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*PoolCollector)(nil)
+
+func NewPoolCollector(connPool *pgxpool.Pool) *PoolCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc("bankita_db_pool_"+name, help, nil, nil)
+	}
+
+	return &PoolCollector{
+		connPool:             connPool,
+		acquiredConns:        desc("acquired_conns", "Number of connections currently in use."),
+		idleConns:            desc("idle_conns", "Number of idle connections."),
+		totalConns:           desc("total_conns", "Number of open connections."),
+		maxConns:             desc("max_conns", "Maximum size of the pool."),
+		acquireCount:         desc("acquire_total", "Number of successful connection acquires."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquireCount:    desc("empty_acquire_total", "Number of acquires that had to wait for a connection."),
+		canceledAcquireCount: desc("canceled_acquire_total", "Number of acquires canceled by their context."),
+	}
+}
+
+func (collector *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(collector, ch)
+}
+
+func (collector *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := collector.connPool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(collector.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(collector.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(collector.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(collector.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(collector.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(collector.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(collector.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(collector.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Account struct {
@@ -16,6 +17,19 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// How far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code string `json:"code"`
+	// Number of decimal places amounts in this currency are stored with
+	MinorUnit int16  `json:"minor_unit"`
+	Symbol    string `json:"symbol"`
+	// Whether new accounts, transfers and quotes may use the currency
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -24,6 +38,32 @@ type Entry struct {
 	// Positive or negative amount representing deposits or withdrawals
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// Transfer the entry belongs to, NULL for deposits, withdrawals and transfer entries the backfill could not match
+	TransferID pgtype.Int8 `json:"transfer_id"`
+}
+
+type FxQuote struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Amount       int64     `json:"amount"`
+	ToAmount     int64     `json:"to_amount"`
+	ExchangeRate string    `json:"exchange_rate"`
+	Spread       string    `json:"spread"`
+	// Set when a transfer executes the quote, a quote can be used once
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username       string    `json:"username"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	RequestHash    string    `json:"request_hash"`
+	Response       []byte    `json:"response"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type Session struct {
@@ -35,15 +75,33 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+	FamilyID     uuid.UUID `json:"family_id"`
+	IsRotated    bool      `json:"is_rotated"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// Positive amount representing transfer value
+	// Positive amount debited from the source account, in its currency
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// Positive amount credited to the target account, in its currency
+	ToAmount int64 `json:"to_amount"`
+	// Applied rate after the spread, 1 for same-currency transfers
+	ExchangeRate string `json:"exchange_rate"`
+	// Fraction taken off the mid-market rate
+	Spread  string        `json:"spread"`
+	QuoteID uuid.NullUUID `json:"quote_id"`
+	// Part of amount kept as the exchange spread, in the source currency. Cross-currency transfers created before this column was added have 0
+	SpreadAmount int64 `json:"spread_amount"`
+}
+
+// Transfers whose entries the statement backfill could not link to them
+type UnmatchedTransferLeg struct {
+	TransferID  int64     `json:"transfer_id"`
+	MissingLegs int32     `json:"missing_legs"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -53,4 +111,6 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// depositor or operator; only operators can deposit and withdraw cash
+	Role string `json:"role"`
 }
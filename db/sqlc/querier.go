@@ -12,22 +12,40 @@ import (
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	BlockUserSessions(ctx context.Context, username string) error
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByOwnerCurrency(ctx context.Context, arg GetAccountByOwnerCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFxQuote(ctx context.Context, id uuid.UUID) (FxQuote, error)
+	GetFxQuoteForUpdate(ctx context.Context, id uuid.UUID) (FxQuote, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveSessions(ctx context.Context, username string) ([]Session, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUnmatchedTransferLegs(ctx context.Context) ([]UnmatchedTransferLeg, error)
+	MarkFxQuoteUsed(ctx context.Context, id uuid.UUID) error
+	RotateSession(ctx context.Context, id uuid.UUID) (Session, error)
+	SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,9 +12,32 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockSessionFamily = `-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE family_id = $1
+`
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, blockSessionFamily, familyID)
+	return err
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :exec
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE username = $1 AND is_blocked = FALSE
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) error {
+	_, err := q.db.Exec(ctx, blockUserSessions, username)
+	return err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
   id, 
+  family_id,
   username, 
   refresh_token,
   user_agent,
@@ -22,13 +45,14 @@ INSERT INTO sessions (
   is_blocked,
   expires_at
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
-RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, is_rotated
 `
 
 type CreateSessionParams struct {
 	ID           uuid.UUID `json:"id"`
+	FamilyID     uuid.UUID `json:"family_id"`
 	Username     string    `json:"username"`
 	RefreshToken string    `json:"refresh_token"`
 	UserAgent    string    `json:"user_agent"`
@@ -38,8 +62,9 @@ type CreateSessionParams struct {
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
-	row := q.db.QueryRowContext(ctx, createSession,
+	row := q.db.QueryRow(ctx, createSession,
 		arg.ID,
+		arg.FamilyID,
 		arg.Username,
 		arg.RefreshToken,
 		arg.UserAgent,
@@ -57,17 +82,84 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.IsRotated,
 	)
 	return i, err
 }
 
 const getSession = `-- name: GetSession :one
-SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, is_rotated FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
-	row := q.db.QueryRowContext(ctx, getSession, id)
+	row := q.db.QueryRow(ctx, getSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.FamilyID,
+		&i.IsRotated,
+	)
+	return i, err
+}
+
+const listActiveSessions = `-- name: ListActiveSessions :many
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, is_rotated FROM sessions
+WHERE username = $1
+  AND is_rotated = FALSE
+  AND is_blocked = FALSE
+  AND expires_at > now()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListActiveSessions(ctx context.Context, username string) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listActiveSessions, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.FamilyID,
+			&i.IsRotated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSession = `-- name: RotateSession :one
+UPDATE sessions
+SET is_rotated = TRUE
+WHERE id = $1 AND is_rotated = FALSE
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, is_rotated
+`
+
+func (q *Queries) RotateSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, rotateSession, id)
 	var i Session
 	err := row.Scan(
 		&i.ID,
@@ -78,6 +170,8 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.IsRotated,
 	)
 	return i, err
 }
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func createRandomSession(t *testing.T, user User) Session {
+	id := uuid.New()
+	arg := CreateSessionParams{
+		ID:           id,
+		FamilyID:     id,
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		IsBlocked:    false,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.RefreshToken, session.RefreshToken)
+	require.False(t, session.IsBlocked)
+	require.False(t, session.IsRotated)
+	require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+	require.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	createRandomSession(t, createRandomUser(t))
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t, createRandomUser(t))
+
+	arg := RotateSessionTxParams{
+		SessionID: session.ID,
+		NewSession: CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     session.Username,
+			RefreshToken: util.RandomString(32),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+	}
+
+	result, err := store.RotateSessionTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.OldSession.IsRotated)
+	require.Equal(t, arg.NewSession.ID, result.NewSession.ID)
+	require.Equal(t, session.FamilyID, result.NewSession.FamilyID)
+	require.False(t, result.NewSession.IsRotated)
+
+	// The same session cannot be rotated twice.
+	arg.NewSession.ID = uuid.New()
+	_, err = store.RotateSessionTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrSessionRotated)
+}
+
+func TestBlockSessionFamily(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+	other := createRandomSession(t, user)
+
+	result, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID: session.ID,
+		NewSession: CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     user.Username,
+			RefreshToken: util.RandomString(32),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+	})
+	require.NoError(t, err)
+
+	err = store.BlockSessionFamily(context.Background(), session.FamilyID)
+	require.NoError(t, err)
+
+	for _, id := range []uuid.UUID{session.ID, result.NewSession.ID} {
+		blocked, err := store.GetSession(context.Background(), id)
+		require.NoError(t, err)
+		require.True(t, blocked.IsBlocked)
+	}
+
+	untouched, err := store.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, untouched.IsBlocked)
+}
+
+func TestListActiveSessions(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+	blocked := createRandomSession(t, user)
+
+	err := testQueries.BlockSessionFamily(context.Background(), blocked.FamilyID)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session.ID, sessions[0].ID)
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomSession(t, user)
+	createRandomSession(t, user)
+
+	err := testQueries.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
@@ -2,50 +2,117 @@ package db
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"math/big"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/superjantung/bankita-api/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ReplayTransferTx(ctx context.Context, arg TransferTxParams, toCurrency string) (TransferTxResult, bool, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error)
+	StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error)
 }
 
 type SQLStore struct {
-	db *sql.DB
-	*Queries
+	connPool *pgxpool.Pool
+	queries  *Queries
 }
 
-func NewStore(db *sql.DB) Store {
+var _ Store = (*SQLStore)(nil)
+
+func NewStore(connPool *pgxpool.Pool) Store {
 	return &SQLStore{
-		db:      db,
-		Queries: New(db),
+		connPool: connPool,
+		queries:  New(connPool),
 	}
 }
 
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// execTx runs fn within a database transaction using opts. When the
+// transaction fails with a serialization failure or a deadlock, the whole
+// callback is retried with backoff, so fn must be safe to run more than once.
+func (store *SQLStore) execTx(ctx context.Context, opts pgx.TxOptions, fn func(*Queries) error) (err error) {
+	ctx, span := tracer.Start(ctx, "execTx")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	for attempt := 1; ; attempt++ {
+		span.SetAttributes(attribute.Int("db.tx.attempts", attempt))
+
+		err = store.runTx(ctx, opts, fn)
+		if err == nil || !errors.Is(err, ErrSerialization) {
+			return err
+		}
+
+		if attempt == maxTxAttempts {
+			txRetriesExhaustedTotal.Inc()
+			return err
+		}
+
+		if !waitTxRetry(ctx, attempt) {
+			return err
+		}
+		txRetriesTotal.WithLabelValues(retryReason(err)).Inc()
+	}
+}
+
+func (store *SQLStore) runTx(ctx context.Context, opts pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.connPool.BeginTx(ctx, opts)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", translateError(err))
 	}
 
 	q := New(tx)
 	err = fn(q)
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			log.Printf("failed to rollback transaction: %v", rbErr)
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			log.Ctx(ctx).Error().Err(rbErr).Msg("failed to rollback transaction")
 		}
-		return fmt.Errorf("transaction failed: %w", err)
+		return fmt.Errorf("transaction failed: %w", translateError(err))
 	}
 
-	return tx.Commit()
+	return translateError(tx.Commit(ctx))
 }
 
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        util.Money `json:"amount"`
+	// Exchange is set for cross-currency transfers. Amount is then debited in
+	// the source currency and Exchange.ToAmount credited in the target one.
+	Exchange *ExchangeParams `json:"exchange,omitempty"`
+	// QuoteID executes the transfer at the rate locked in by an exchange
+	// quote instead of Exchange. The quote can only be used once.
+	QuoteID *uuid.UUID `json:"quote_id,omitempty"`
+	// Idempotency is optional. When set, retrying the same transfer with the
+	// same key returns the original result instead of moving money twice.
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// ExchangeParams records the conversion applied to a cross-currency transfer.
+// Rate and Spread are decimal strings.
+type ExchangeParams struct {
+	ToAmount util.Money `json:"to_amount"`
+	Rate     string     `json:"rate"`
+	Spread   string     `json:"spread"`
 }
 
 type TransferTxResult struct {
@@ -59,71 +126,224 @@ type TransferTxResult struct {
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q *Queries) error {
+		if arg.Idempotency != nil {
+			replayed, err := claimIdempotencyKey(ctx, q, *arg.Idempotency, newTransferRequest(arg, arg.toCurrency()), &result)
+			if err != nil || replayed {
+				return err
+			}
+		}
+
 		var err error
+		exchange := ExchangeParams{ToAmount: arg.Amount, Rate: "1", Spread: "0"}
+		var quoteID uuid.NullUUID
+		switch {
+		case arg.QuoteID != nil:
+			exchange, err = useFxQuote(ctx, q, *arg.QuoteID, arg.Amount)
+			if err != nil {
+				return fmt.Errorf("failed to use exchange quote: %w", err)
+			}
+			quoteID = uuid.NullUUID{UUID: *arg.QuoteID, Valid: true}
+		case arg.Exchange != nil:
+			exchange = *arg.Exchange
+		}
+
+		changes := []balanceChange{
+			{accountID: arg.FromAccountID, amount: negate(arg.Amount)},
+			{accountID: arg.ToAccountID, amount: exchange.ToAmount},
+		}
+
+		// A cross-currency transfer pays into the settlement account of the
+		// source currency and out of the one of the target currency, so that
+		// every currency balances on its own.
+		var spread int64
+		if exchange.ToAmount.Currency != arg.Amount.Currency {
+			fromSettlement, err := getSettlementAccount(ctx, q, arg.Amount.Currency)
+			if err != nil {
+				return err
+			}
+			toSettlement, err := getSettlementAccount(ctx, q, exchange.ToAmount.Currency)
+			if err != nil {
+				return err
+			}
+
+			changes = append(changes,
+				balanceChange{accountID: fromSettlement.ID, amount: arg.Amount},
+				balanceChange{accountID: toSettlement.ID, amount: negate(exchange.ToAmount)},
+			)
+
+			spread, err = spreadAmount(arg.Amount.Amount, exchange.Spread)
+			if err != nil {
+				return err
+			}
+		}
+
 		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
 			FromAccountID: arg.FromAccountID,
 			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+			Amount:        arg.Amount.Amount,
+			ToAmount:      exchange.ToAmount.Amount,
+			ExchangeRate:  exchange.Rate,
+			Spread:        exchange.Spread,
+			SpreadAmount:  spread,
+			QuoteID:       quoteID,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create transfer: %w", err)
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create 'from' entry: %w", err)
+		entries := make([]Entry, len(changes))
+		for i, change := range changes {
+			entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID:  change.accountID,
+				Amount:     change.amount.Amount,
+				TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create entry for account [%d]: %w", change.accountID, err)
+			}
 		}
+		result.FromEntry, result.ToEntry = entries[0], entries[1]
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+		accounts, err := addBalances(ctx, q, changes)
 		if err != nil {
-			return fmt.Errorf("failed to create 'to' entry: %w", err)
+			return fmt.Errorf("failed to add money: %w", err)
 		}
+		result.FromAccount, result.ToAccount = accounts[arg.FromAccountID], accounts[arg.ToAccountID]
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-			if err != nil {
-				fmt.Printf("error while adding money: %v", err)
-				return err
-			}
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-			if err != nil {
-				fmt.Printf("error while adding money: %v", err)
-				return err
-			}
+		// The balance update above holds the row lock on the 'from' account,
+		// so the check cannot race with another transfer from it.
+		if !result.FromAccount.HasSufficientFunds() {
+			return ErrInsufficientFunds
+		}
+
+		if arg.Idempotency != nil {
+			return saveIdempotentResponse(ctx, q, *arg.Idempotency, result)
 		}
 
 		return nil
 	})
 
+	observeTransfer(arg.Amount.Currency, err)
 	if err != nil {
-		log.Printf("transfer transaction failed: %v", err)
-		return TransferTxResult{}, fmt.Errorf("failed to perform transfer transaction")
+		log.Ctx(ctx).Error().Err(err).Msg("transfer transaction failed")
+		return TransferTxResult{}, fmt.Errorf("failed to perform transfer transaction: %w", err)
 	}
 
-	return result, err
+	return result, nil
 }
 
-func addMoney(ctx context.Context, q *Queries, fromAccountID int64, amount1 int64, toAccountID int64, amount2 int64) (fromAccount Account, toAccount Account, err error) {
-	fromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     fromAccountID,
-		Amount: amount1,
-	})
+// transferRequest holds the fields of a transfer chosen by the client. The
+// idempotency key is bound to them rather than to TransferTxParams, whose
+// exchange rate is looked up live and would make a retry look like a
+// different request.
+type transferRequest struct {
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        util.Money `json:"amount"`
+	ToCurrency    string     `json:"to_currency"`
+	QuoteID       *uuid.UUID `json:"quote_id,omitempty"`
+}
+
+// newTransferRequest binds a transfer to the currency credited to the target
+// account. Quote transfers are bound to the quote instead, which fixes both
+// currencies.
+func newTransferRequest(arg TransferTxParams, toCurrency string) transferRequest {
+	request := transferRequest{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToCurrency:    toCurrency,
+		QuoteID:       arg.QuoteID,
+	}
+	if arg.QuoteID != nil {
+		request.ToCurrency = arg.Amount.Currency
+	}
+	return request
+}
 
+// toCurrency returns the currency credited to the target account.
+func (arg TransferTxParams) toCurrency() string {
+	if arg.Exchange != nil {
+		return arg.Exchange.ToAmount.Currency
+	}
+	return arg.Amount.Currency
+}
+
+// ReplayTransferTx returns the result recorded for a transfer that was already
+// executed under arg.Idempotency, so that a retry is answered before exchange
+// rates are looked up or accounts are checked again. arg does not need an
+// Exchange yet; toCurrency stands in for it. It reports false when there is no
+// key or the key is unused, and ErrIdempotencyKeyMismatch when the key was
+// used for a different transfer.
+func (store *SQLStore) ReplayTransferTx(ctx context.Context, arg TransferTxParams, toCurrency string) (TransferTxResult, bool, error) {
+	var result TransferTxResult
+	if arg.Idempotency == nil {
+		return result, false, nil
+	}
+
+	replayed, err := replayIdempotencyKey(ctx, store.queries, *arg.Idempotency, newTransferRequest(arg, toCurrency), &result)
 	if err != nil {
-		return
+		return TransferTxResult{}, false, translateError(err)
 	}
+	return result, replayed, nil
+}
+
+// balanceChange adds amount, negative for a debit, to the balance of an
+// account.
+type balanceChange struct {
+	accountID int64
+	amount    util.Money
+}
+
+func negate(money util.Money) util.Money {
+	return util.NewMoney(-money.Amount, money.Currency)
+}
 
-	toAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     toAccountID,
-		Amount: amount2,
+// addBalances applies the changes in account ID order, the same order in
+// every transaction, so that concurrent transfers touching the same accounts
+// cannot deadlock. A change in another currency than its account fails with
+// util.ErrCurrencyMismatch and one that would overflow the balance with
+// util.ErrAmountOverflow. It returns the updated accounts by ID.
+func addBalances(ctx context.Context, q *Queries, changes []balanceChange) (map[int64]Account, error) {
+	sorted := append([]balanceChange(nil), changes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].accountID < sorted[j].accountID
 	})
-	return
+
+	accounts := make(map[int64]Account, len(sorted))
+	for _, change := range sorted {
+		account, err := q.GetAccountForUpdate(ctx, change.accountID)
+		if err != nil {
+			return nil, err
+		}
+
+		balance := util.NewMoney(account.Balance, account.Currency)
+		if _, err := balance.Add(change.amount); err != nil {
+			return nil, err
+		}
+
+		account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     change.accountID,
+			Amount: change.amount.Amount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		accounts[change.accountID] = account
+	}
+	return accounts, nil
+}
+
+// spreadAmount returns the part of amount that spread, a decimal fraction,
+// takes off a conversion, rounded down.
+func spreadAmount(amount int64, spread string) (int64, error) {
+	fraction, ok := new(big.Rat).SetString(spread)
+	if !ok {
+		return 0, fmt.Errorf("invalid spread: %q", spread)
+	}
+
+	value := new(big.Int).Mul(big.NewInt(amount), fraction.Num())
+	value.Quo(value, fraction.Denom())
+	return value.Int64(), nil
 }
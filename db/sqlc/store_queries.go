@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// The methods below wrap the generated queries so that every error leaving
+// the Store goes through translateError.
+
+func (store *SQLStore) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	account, err := store.queries.AddAccountBalance(ctx, arg)
+	return account, translateError(err)
+}
+
+func (store *SQLStore) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	return translateError(store.queries.BlockSessionFamily(ctx, familyID))
+}
+
+func (store *SQLStore) BlockUserSessions(ctx context.Context, username string) error {
+	return translateError(store.queries.BlockUserSessions(ctx, username))
+}
+
+func (store *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	account, err := store.queries.CreateAccount(ctx, arg)
+	return account, translateError(err)
+}
+
+func (store *SQLStore) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	entry, err := store.queries.CreateEntry(ctx, arg)
+	return entry, translateError(err)
+}
+
+func (store *SQLStore) CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error) {
+	quote, err := store.queries.CreateFxQuote(ctx, arg)
+	return quote, translateError(err)
+}
+
+func (store *SQLStore) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	key, err := store.queries.CreateIdempotencyKey(ctx, arg)
+	return key, translateError(err)
+}
+
+func (store *SQLStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	session, err := store.queries.CreateSession(ctx, arg)
+	return session, translateError(err)
+}
+
+func (store *SQLStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	transfer, err := store.queries.CreateTransfer(ctx, arg)
+	return transfer, translateError(err)
+}
+
+func (store *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	user, err := store.queries.CreateUser(ctx, arg)
+	return user, translateError(err)
+}
+
+func (store *SQLStore) DeleteAccount(ctx context.Context, id int64) error {
+	return translateError(store.queries.DeleteAccount(ctx, id))
+}
+
+func (store *SQLStore) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	count, err := store.queries.DeleteExpiredIdempotencyKeys(ctx)
+	return count, translateError(err)
+}
+
+func (store *SQLStore) GetAccount(ctx context.Context, id int64) (Account, error) {
+	account, err := store.queries.GetAccount(ctx, id)
+	return account, translateError(err)
+}
+
+func (store *SQLStore) GetAccountByOwnerCurrency(ctx context.Context, arg GetAccountByOwnerCurrencyParams) (Account, error) {
+	account, err := store.queries.GetAccountByOwnerCurrency(ctx, arg)
+	return account, translateError(err)
+}
+
+func (store *SQLStore) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	account, err := store.queries.GetAccountForUpdate(ctx, id)
+	return account, translateError(err)
+}
+
+func (store *SQLStore) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	entry, err := store.queries.GetEntry(ctx, id)
+	return entry, translateError(err)
+}
+
+func (store *SQLStore) GetFxQuote(ctx context.Context, id uuid.UUID) (FxQuote, error) {
+	quote, err := store.queries.GetFxQuote(ctx, id)
+	return quote, translateError(err)
+}
+
+func (store *SQLStore) GetFxQuoteForUpdate(ctx context.Context, id uuid.UUID) (FxQuote, error) {
+	quote, err := store.queries.GetFxQuoteForUpdate(ctx, id)
+	return quote, translateError(err)
+}
+
+func (store *SQLStore) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	key, err := store.queries.GetIdempotencyKey(ctx, arg)
+	return key, translateError(err)
+}
+
+func (store *SQLStore) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	session, err := store.queries.GetSession(ctx, id)
+	return session, translateError(err)
+}
+
+func (store *SQLStore) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
+	transfer, err := store.queries.GetTransfer(ctx, id)
+	return transfer, translateError(err)
+}
+
+func (store *SQLStore) GetUser(ctx context.Context, username string) (User, error) {
+	user, err := store.queries.GetUser(ctx, username)
+	return user, translateError(err)
+}
+
+func (store *SQLStore) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	accounts, err := store.queries.ListAccounts(ctx, arg)
+	return accounts, translateError(err)
+}
+
+func (store *SQLStore) ListActiveSessions(ctx context.Context, username string) ([]Session, error) {
+	sessions, err := store.queries.ListActiveSessions(ctx, username)
+	return sessions, translateError(err)
+}
+
+func (store *SQLStore) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	currencies, err := store.queries.ListCurrencies(ctx)
+	return currencies, translateError(err)
+}
+
+func (store *SQLStore) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	entries, err := store.queries.ListEntries(ctx, arg)
+	return entries, translateError(err)
+}
+
+func (store *SQLStore) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	entries, err := store.queries.ListStatementEntries(ctx, arg)
+	return entries, translateError(err)
+}
+
+func (store *SQLStore) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	transfers, err := store.queries.ListTransfers(ctx, arg)
+	return transfers, translateError(err)
+}
+
+func (store *SQLStore) ListUnmatchedTransferLegs(ctx context.Context) ([]UnmatchedTransferLeg, error) {
+	legs, err := store.queries.ListUnmatchedTransferLegs(ctx)
+	return legs, translateError(err)
+}
+
+func (store *SQLStore) MarkFxQuoteUsed(ctx context.Context, id uuid.UUID) error {
+	return translateError(store.queries.MarkFxQuoteUsed(ctx, id))
+}
+
+func (store *SQLStore) RotateSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	session, err := store.queries.RotateSession(ctx, id)
+	return session, translateError(err)
+}
+
+func (store *SQLStore) SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error) {
+	total, err := store.queries.SumEntriesSince(ctx, arg)
+	return total, translateError(err)
+}
+
+func (store *SQLStore) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	account, err := store.queries.UpdateAccount(ctx, arg)
+	return account, translateError(err)
+}
+
+func (store *SQLStore) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	account, err := store.queries.UpdateAccountOverdraftLimit(ctx, arg)
+	return account, translateError(err)
+}
+
+func (store *SQLStore) UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error {
+	return translateError(store.queries.UpdateIdempotencyKeyResponse(ctx, arg))
+}
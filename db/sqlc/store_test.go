@@ -3,22 +3,27 @@ package db
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
 )
 
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	fromAccount := createRandomAccount(t)
-	toAccount := createRandomAccount(t)
-	fmt.Println(">> before:", fromAccount.Balance, toAccount.Balance)
-
 	// Run concurrent transactions
 	n := 5
 	amount := int64(100000)
 
+	fromAccount := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+	fmt.Println(">> before:", fromAccount.Balance, toAccount.Balance)
+
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
 
@@ -28,7 +33,7 @@ func TestTransferTx(t *testing.T) {
 			result, err := store.TransferTx(ctx, TransferTxParams{
 				FromAccountID: fromAccount.ID,
 				ToAccountID:   toAccount.ID,
-				Amount:        amount,
+				Amount:        util.NewMoney(amount, fromAccount.Currency),
 			})
 
 			errs <- err
@@ -117,14 +122,14 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	fromAccount := createRandomAccount(t)
-	toAccount := createRandomAccount(t)
-	fmt.Println(">> before:", fromAccount.Balance, toAccount.Balance)
-
 	// Run concurrent transactions
 	n := 10
 	amount := int64(100000)
 
+	fromAccount := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	toAccount := fundAccount(t, createRandomAccountInCurrency(t, fromAccount.Currency), int64(n)*amount)
+	fmt.Println(">> before:", fromAccount.Balance, toAccount.Balance)
+
 	errs := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -141,7 +146,7 @@ func TestTransferTxDeadlock(t *testing.T) {
 			_, err := store.TransferTx(ctx, TransferTxParams{
 				FromAccountID: fromAccountId,
 				ToAccountID:   toAccountId,
-				Amount:        amount,
+				Amount:        util.NewMoney(amount, fromAccount.Currency),
 			})
 
 			errs <- err
@@ -166,3 +171,338 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, fromAccount.Balance, updatedAccount1.Balance)
 	require.Equal(t, toAccount.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(fromAccount.Balance+1, fromAccount.Currency),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// The rolled back transfer must leave both balances untouched.
+	updatedAccount1, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount.Balance, updatedAccount2.Balance)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+	overdraftLimit := int64(1000)
+
+	fromAccount, err := store.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             fromAccount.ID,
+		OverdraftLimit: overdraftLimit,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(fromAccount.Balance+overdraftLimit, fromAccount.Currency),
+	})
+	require.NoError(t, err)
+	require.Equal(t, -overdraftLimit, result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(1, fromAccount.Currency),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxBalanceOverflow(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := fundAccount(t, createRandomAccount(t), 10)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+	toAccount = fundAccount(t, toAccount, math.MaxInt64-toAccount.Balance)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(10, fromAccount.Currency),
+	})
+	require.ErrorIs(t, err, util.ErrAmountOverflow)
+}
+
+func TestTransferTxExchange(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+	fromAccount := fundAccount(t, createRandomAccountInCurrency(t, util.USD), amount)
+	toAccount := createRandomAccountInCurrency(t, util.IDR)
+	usdSettlement, err := getSettlementAccount(context.Background(), New(testDB), util.USD)
+	require.NoError(t, err)
+	idrSettlement, err := getSettlementAccount(context.Background(), New(testDB), util.IDR)
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(amount, util.USD),
+		Exchange: &ExchangeParams{
+			ToAmount: util.NewMoney(1534500, util.IDR),
+			Rate:     "15345",
+			Spread:   "0.01",
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, amount, result.Transfer.Amount)
+	require.Equal(t, int64(1534500), result.Transfer.ToAmount)
+	require.Equal(t, "15345.000000000000", result.Transfer.ExchangeRate)
+	require.Equal(t, "0.010000", result.Transfer.Spread)
+	require.Equal(t, int64(1), result.Transfer.SpreadAmount)
+
+	require.Equal(t, -amount, result.FromEntry.Amount)
+	require.Equal(t, int64(1534500), result.ToEntry.Amount)
+	require.Equal(t, fromAccount.Balance-amount, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+1534500, result.ToAccount.Balance)
+
+	// Each currency balances through its settlement account.
+	updatedUSDSettlement, err := store.GetAccount(context.Background(), usdSettlement.ID)
+	require.NoError(t, err)
+	require.Equal(t, usdSettlement.Balance+amount, updatedUSDSettlement.Balance)
+
+	updatedIDRSettlement, err := store.GetAccount(context.Background(), idrSettlement.ID)
+	require.NoError(t, err)
+	require.Equal(t, idrSettlement.Balance-1534500, updatedIDRSettlement.Balance)
+}
+
+func TestTransferTxQuote(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+	fromAccount := fundAccount(t, createRandomAccountInCurrency(t, util.USD), 2*amount)
+	toAccount := createRandomAccountInCurrency(t, util.IDR)
+	quote := createRandomFxQuote(t, fromAccount.Owner, amount, time.Now().Add(time.Minute))
+
+	arg := TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(amount, util.USD),
+		QuoteID:       &quote.ID,
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, quote.ToAmount, result.Transfer.ToAmount)
+	require.Equal(t, quote.ExchangeRate, result.Transfer.ExchangeRate)
+	require.Equal(t, quote.ID, result.Transfer.QuoteID.UUID)
+	require.Equal(t, toAccount.Balance+quote.ToAmount, result.ToAccount.Balance)
+
+	// A quote executes a single transfer.
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrQuoteUsed)
+
+	expired := createRandomFxQuote(t, fromAccount.Owner, amount, time.Now().Add(-time.Second))
+	arg.QuoteID = &expired.ID
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrQuoteExpired)
+
+	updatedAccount, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-amount, updatedAccount.Balance)
+}
+
+func TestTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(10)
+	fromAccount := fundAccount(t, createRandomAccount(t), amount)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+
+	arg := TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(amount, fromAccount.Currency),
+		Idempotency: &IdempotencyParams{
+			Key:       util.RandomString(16),
+			Username:  fromAccount.Owner,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// Replaying the request returns the original result without moving money.
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+
+	updatedAccount, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-amount, updatedAccount.Balance)
+
+	// Reusing the key for a different request is rejected.
+	arg.Amount = util.NewMoney(amount+1, fromAccount.Currency)
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyMismatch)
+}
+
+func TestTransferTxIdempotencyRateChange(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+	fromAccount := fundAccount(t, createRandomAccountInCurrency(t, util.USD), amount)
+	toAccount := createRandomAccountInCurrency(t, util.IDR)
+
+	arg := TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(amount, util.USD),
+		Exchange: &ExchangeParams{
+			ToAmount: util.NewMoney(1534500, util.IDR),
+			Rate:     "15345",
+			Spread:   "0.01",
+		},
+		Idempotency: &IdempotencyParams{
+			Key:       util.RandomString(16),
+			Username:  fromAccount.Owner,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// A retry converted at a newer rate is still the same request.
+	arg.Exchange = &ExchangeParams{
+		ToAmount: util.NewMoney(1535000, util.IDR),
+		Rate:     "15350",
+		Spread:   "0.01",
+	}
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.Transfer.ToAmount, result2.Transfer.ToAmount)
+}
+
+func TestReplayTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+	fromAccount := fundAccount(t, createRandomAccountInCurrency(t, util.USD), amount)
+	toAccount := createRandomAccountInCurrency(t, util.IDR)
+
+	arg := TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(amount, util.USD),
+		Idempotency: &IdempotencyParams{
+			Key:       util.RandomString(16),
+			Username:  fromAccount.Owner,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+
+	// Nothing is recorded before the first attempt.
+	_, replayed, err := store.ReplayTransferTx(context.Background(), arg, util.IDR)
+	require.NoError(t, err)
+	require.False(t, replayed)
+
+	arg.Exchange = &ExchangeParams{
+		ToAmount: util.NewMoney(1534500, util.IDR),
+		Rate:     "15345",
+		Spread:   "0.01",
+	}
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// A retry is matched before its exchange is priced.
+	arg.Exchange = nil
+	result2, replayed, err := store.ReplayTransferTx(context.Background(), arg, util.IDR)
+	require.NoError(t, err)
+	require.True(t, replayed)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.Transfer.ToAmount, result2.Transfer.ToAmount)
+
+	_, _, err = store.ReplayTransferTx(context.Background(), arg, util.USD)
+	require.ErrorIs(t, err, ErrIdempotencyKeyMismatch)
+}
+
+func TestTransferTxExpiredIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(10)
+	fromAccount := fundAccount(t, createRandomAccount(t), 2*amount)
+	toAccount := createRandomAccountInCurrency(t, fromAccount.Currency)
+
+	arg := TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.NewMoney(amount, fromAccount.Currency),
+		Idempotency: &IdempotencyParams{
+			Key:       util.RandomString(16),
+			Username:  fromAccount.Owner,
+			ExpiresAt: time.Now().Add(-time.Second),
+		},
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// An expired key no longer deduplicates, so the transfer runs again.
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEqual(t, result1.Transfer.ID, result2.Transfer.ID)
+
+	count, err := store.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, count, int64(1))
+
+	_, err = store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       arg.Idempotency.Username,
+		IdempotencyKey: arg.Idempotency.Key,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestExecTxRetry(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+
+	attempts := 0
+	err := store.execTx(context.Background(), pgx.TxOptions{}, func(q *Queries) error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: SerializationFailure}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	// Other errors are returned without retrying.
+	attempts = 0
+	err = store.execTx(context.Background(), pgx.TxOptions{}, func(q *Queries) error {
+		attempts++
+		return ErrInsufficientFunds
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Equal(t, 1, attempts)
+
+	// Retries stop once maxTxAttempts is reached.
+	attempts = 0
+	err = store.execTx(context.Background(), pgx.TxOptions{}, func(q *Queries) error {
+		attempts++
+		return &pgconn.PgError{Code: DeadlockDetected}
+	})
+	require.ErrorIs(t, err, ErrSerialization)
+	require.Equal(t, maxTxAttempts, attempts)
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/superjantung/bankita-api/db/sqlc")
+
+// QueryTracer creates a span for every query run through pgx. Set it as the
+// Tracer of the pgx connection config.
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := queryName(data.SQL)
+	ctx, _ = tracer.Start(ctx, "db.query "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(name),
+			semconv.DBStatement(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil && data.Err != pgx.ErrNoRows {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// queryName returns the sqlc query name from the "-- name: GetAccount :one"
+// comment that sqlc puts in front of every generated query.
+func queryName(sql string) string {
+	const prefix = "-- name: "
+	if strings.HasPrefix(sql, prefix) {
+		fields := strings.Fields(strings.TrimPrefix(sql, prefix))
+		if len(fields) > 0 {
+			return fields[0]
+		}
+	}
+
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "query"
+}
@@ -0,0 +1,13 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetAccount", queryName(getAccount))
+	require.Equal(t, "BEGIN", queryName("begin"))
+	require.Equal(t, "query", queryName(""))
+}
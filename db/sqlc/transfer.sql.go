@@ -7,26 +7,47 @@ package db
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  exchange_rate,
+  spread,
+  spread_amount,
+  quote_id
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread, quote_id, spread_amount
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ToAmount      int64         `json:"to_amount"`
+	ExchangeRate  string        `json:"exchange_rate"`
+	Spread        string        `json:"spread"`
+	SpreadAmount  int64         `json:"spread_amount"`
+	QuoteID       uuid.NullUUID `json:"quote_id"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.Spread,
+		arg.SpreadAmount,
+		arg.QuoteID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,17 +55,22 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Spread,
+		&i.QuoteID,
+		&i.SpreadAmount,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread, quote_id, spread_amount FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, getTransfer, id)
+	row := q.db.QueryRow(ctx, getTransfer, id)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -52,12 +78,17 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Spread,
+		&i.QuoteID,
+		&i.SpreadAmount,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread, quote_id, spread_amount FROM transfers
 WHERE 
     from_account_id = $1 OR
     to_account_id = $2
@@ -74,7 +105,7 @@ type ListTransfersParams struct {
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers,
+	rows, err := q.db.Query(ctx, listTransfers,
 		arg.FromAccountID,
 		arg.ToAccountID,
 		arg.Limit,
@@ -93,14 +124,41 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Spread,
+			&i.QuoteID,
+			&i.SpreadAmount,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+const listUnmatchedTransferLegs = `-- name: ListUnmatchedTransferLegs :many
+SELECT transfer_id, missing_legs, created_at FROM unmatched_transfer_legs
+ORDER BY transfer_id
+`
+
+func (q *Queries) ListUnmatchedTransferLegs(ctx context.Context) ([]UnmatchedTransferLeg, error) {
+	rows, err := q.db.Query(ctx, listUnmatchedTransferLegs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UnmatchedTransferLeg{}
+	for rows.Next() {
+		var i UnmatchedTransferLeg
+		if err := rows.Scan(&i.TransferID, &i.MissingLegs, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -14,6 +14,7 @@ func assertTransferCreated(t *testing.T, transfer Transfer, arg CreateTransferPa
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
 	require.NotZero(t, transfer.CreatedAt)
 }
 
@@ -26,10 +27,14 @@ func assertTransferEqual(t *testing.T, expected, actual Transfer) {
 }
 
 func createRandomTransfer(t *testing.T, fromAccount, toAccount Account) Transfer {
+	amount := util.RandomBalance()
 	arg := CreateTransferParams{
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
-		Amount:        util.RandomBalance(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		Spread:        "0",
 	}
 
 	createdTransfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -87,3 +92,16 @@ func TestListTransfers(t *testing.T) {
 		require.True(t, retrievedTransfer.FromAccountID == fromAccount.ID || retrievedTransfer.ToAccountID == fromAccount.ID)
 	}
 }
+
+func TestListUnmatchedTransferLegs(t *testing.T) {
+	transfer := createRandomTransfer(t, createRandomAccount(t), createRandomAccount(t))
+
+	// Only transfers the statement backfill could not match are recorded, not
+	// ones created afterwards.
+	legs, err := testQueries.ListUnmatchedTransferLegs(context.Background())
+	require.NoError(t, err)
+	for _, leg := range legs {
+		require.NotEqual(t, transfer.ID, leg.TransferID)
+		require.Positive(t, leg.MissingLegs)
+	}
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/superjantung/bankita-api/util"
+)
+
+// SettlementAccountOwner owns the cash accounts that back deposits and
+// withdrawals, one per currency. They are contra accounts: their balance is
+// the bank's position in the currency and may go negative without bound, so
+// no overdraft limit applies to them.
+const SettlementAccountOwner = "_settlement"
+
+type DepositTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type DepositTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// DepositTx credits the account and debits the settlement account of the
+// same currency within a single database transaction.
+func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error) {
+	var result DepositTxResult
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q *Queries) error {
+		var err error
+		result.Account, result.Entry, err = moveCash(ctx, q, arg.AccountID, arg.Amount)
+		return err
+	})
+
+	return result, err
+}
+
+type WithdrawTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type WithdrawTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// WithdrawTx debits the account and credits the settlement account of the
+// same currency. It fails with ErrInsufficientFunds when the account balance
+// would drop below its overdraft limit.
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error) {
+	var result WithdrawTxResult
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q *Queries) error {
+		var err error
+		result.Account, result.Entry, err = moveCash(ctx, q, arg.AccountID, -arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		if !result.Account.HasSufficientFunds() {
+			return ErrInsufficientFunds
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// moveCash books amount on the account and the opposite amount on the
+// settlement account, updating balances in account ID order like TransferTx.
+func moveCash(ctx context.Context, q *Queries, accountID int64, amount int64) (account Account, entry Entry, err error) {
+	account, err = q.GetAccount(ctx, accountID)
+	if err != nil {
+		return
+	}
+
+	settlement, err := getSettlementAccount(ctx, q, account.Currency)
+	if err != nil {
+		return
+	}
+
+	entry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: accountID,
+		Amount:    amount,
+	})
+	if err != nil {
+		return
+	}
+
+	_, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: settlement.ID,
+		Amount:    -amount,
+	})
+	if err != nil {
+		return
+	}
+
+	cash := util.NewMoney(amount, account.Currency)
+	accounts, err := addBalances(ctx, q, []balanceChange{
+		{accountID: accountID, amount: cash},
+		{accountID: settlement.ID, amount: negate(cash)},
+	})
+	if err != nil {
+		return
+	}
+	account = accounts[accountID]
+	return
+}
+
+// getSettlementAccount returns the settlement account of currency. A missing
+// one, e.g. for a newly enabled currency, fails with
+// ErrSettlementAccountNotFound.
+func getSettlementAccount(ctx context.Context, q *Queries, currency string) (Account, error) {
+	settlement, err := q.GetAccountByOwnerCurrency(ctx, GetAccountByOwnerCurrencyParams{
+		Owner:    SettlementAccountOwner,
+		Currency: currency,
+	})
+	if err == pgx.ErrNoRows {
+		return Account{}, fmt.Errorf("%w: %s", ErrSettlementAccountNotFound, currency)
+	}
+	return settlement, err
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	settlement, err := getSettlementAccount(context.Background(), New(testDB), account.Currency)
+	require.NoError(t, err)
+
+	// A deposit larger than the settlement balance drives the settlement
+	// account negative instead of failing.
+	amount := settlement.Balance + 5000
+	if amount < 5000 {
+		amount = 5000
+	}
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance+amount, result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, amount, result.Entry.Amount)
+
+	_, err = store.GetEntry(context.Background(), result.Entry.ID)
+	require.NoError(t, err)
+
+	updatedSettlement, err := store.GetAccount(context.Background(), settlement.ID)
+	require.NoError(t, err)
+	require.Equal(t, settlement.Balance-amount, updatedSettlement.Balance)
+	require.Negative(t, updatedSettlement.Balance)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	amount := account.Balance
+
+	result, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Account.Balance)
+	require.Equal(t, -amount, result.Entry.Amount)
+}
+
+func TestWithdrawTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	_, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// The failed withdrawal must leave the balance untouched.
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
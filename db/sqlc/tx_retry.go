@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	maxTxAttempts     = 5
+	txRetryBaseDelay  = 10 * time.Millisecond
+	txRetryMaxBackoff = 500 * time.Millisecond
+)
+
+// waitTxRetry sleeps before the next attempt using exponential backoff with
+// full jitter. It returns false when the context is done or its deadline
+// would pass before the next attempt could start.
+func waitTxRetry(ctx context.Context, attempt int) bool {
+	backoff := txRetryBaseDelay << uint(attempt-1)
+	if backoff > txRetryMaxBackoff {
+		backoff = txRetryMaxBackoff
+	}
+	delay := time.Duration(rand.Int63n(int64(backoff)) + 1)
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func retryReason(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return "unknown"
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrSessionRotated = errors.New("session has already been rotated")
+
+type RotateSessionTxParams struct {
+	SessionID  uuid.UUID           `json:"session_id"`
+	NewSession CreateSessionParams `json:"new_session"`
+}
+
+type RotateSessionTxResult struct {
+	OldSession Session `json:"old_session"`
+	NewSession Session `json:"new_session"`
+}
+
+// RotateSessionTx marks the session as rotated and creates its successor in
+// the same family. It returns ErrSessionRotated when the session was already
+// rotated, which means its refresh token is being reused.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+
+	err := store.execTx(ctx, pgx.TxOptions{}, func(q *Queries) error {
+		var err error
+		result.OldSession, err = q.RotateSession(ctx, arg.SessionID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrSessionRotated
+			}
+			return err
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = result.OldSession.FamilyID
+		result.NewSession, err = q.CreateSession(ctx, newSession)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type StatementTxParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// StatementEntry is an entry with the account balance right after it and,
+// for transfers, the account on the other side. Transfer entries booked
+// before entries were linked to transfers may lack a counterparty when the
+// backfill in migration 000011 could not match them.
+type StatementEntry struct {
+	Entry
+	Balance               int64  `json:"balance"`
+	CounterpartyAccountID int64  `json:"counterparty_account_id,omitempty"`
+	CounterpartyOwner     string `json:"counterparty_owner,omitempty"`
+}
+
+type StatementTxResult struct {
+	Account        Account          `json:"account"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance int64            `json:"opening_balance"`
+	ClosingBalance int64            `json:"closing_balance"`
+	Entries        []StatementEntry `json:"entries"`
+}
+
+// StatementTx lists the entries of the account created in [From, To) with
+// the balances around them. All reads share one repeatable read snapshot, so
+// a concurrent transfer either shows up in the balances and the entries or in
+// neither. Balances are derived backwards from the current balance because
+// the initial balance of an account has no entry.
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error) {
+	var result StatementTxResult
+
+	opts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := store.execTx(ctx, opts, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		sinceFrom, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			CreatedAt: arg.From,
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err := q.ListStatementEntries(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.From = arg.From
+		result.To = arg.To
+		result.OpeningBalance = result.Account.Balance - sinceFrom
+		result.ClosingBalance = result.OpeningBalance
+		result.Entries = make([]StatementEntry, len(rows))
+		for i, row := range rows {
+			result.Entries[i] = StatementEntry{
+				Entry: Entry{
+					ID:         row.ID,
+					AccountID:  row.AccountID,
+					Amount:     row.Amount,
+					CreatedAt:  row.CreatedAt,
+					TransferID: row.TransferID,
+				},
+				Balance:               result.OpeningBalance + row.CumulativeAmount,
+				CounterpartyAccountID: row.CounterpartyAccountID.Int64,
+				CounterpartyOwner:     row.CounterpartyOwner.String,
+			}
+			result.ClosingBalance = result.Entries[i].Balance
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func TestStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	other := createRandomAccountInCurrency(t, account.Currency)
+	from := time.Now().Add(-time.Second)
+
+	deposit, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    500,
+	})
+	require.NoError(t, err)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   other.ID,
+		Amount:        util.NewMoney(200, account.Currency),
+	})
+	require.NoError(t, err)
+
+	statement, err := store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account.ID,
+		From:      from,
+		To:        time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance, statement.OpeningBalance)
+	require.Equal(t, account.Balance+300, statement.ClosingBalance)
+	require.Equal(t, transfer.FromAccount.Balance, statement.Account.Balance)
+	require.Len(t, statement.Entries, 2)
+
+	require.Equal(t, deposit.Entry.ID, statement.Entries[0].ID)
+	require.Equal(t, account.Balance+500, statement.Entries[0].Balance)
+	require.Zero(t, statement.Entries[0].CounterpartyAccountID)
+
+	require.Equal(t, transfer.FromEntry.ID, statement.Entries[1].ID)
+	require.Equal(t, transfer.Transfer.ID, statement.Entries[1].TransferID.Int64)
+	require.Equal(t, account.Balance+300, statement.Entries[1].Balance)
+	require.Equal(t, other.ID, statement.Entries[1].CounterpartyAccountID)
+	require.Equal(t, other.Owner, statement.Entries[1].CounterpartyOwner)
+
+	// Entries after the period are excluded from both the list and the
+	// closing balance.
+	statement, err = store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account.ID,
+		From:      from.Add(-time.Hour),
+		To:        from,
+	})
+	require.NoError(t, err)
+	require.Empty(t, statement.Entries)
+	require.Equal(t, account.Balance, statement.OpeningBalance)
+	require.Equal(t, account.Balance, statement.ClosingBalance)
+}
@@ -45,6 +45,7 @@ func createRandomUser(t *testing.T) User {
 	require.NotEmpty(t, createdUser)
 
 	assertUserCreated(t, createdUser, arg)
+	require.Equal(t, util.DepositorRole, createdUser.Role)
 	return createdUser
 }
 
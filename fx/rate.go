@@ -0,0 +1,120 @@
+// Package fx converts amounts between currencies for cross-currency transfers.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/superjantung/bankita-api/util"
+)
+
+const (
+	// RateScale and SpreadScale match the precision of the transfers table.
+	RateScale   = 12
+	SpreadScale = 6
+)
+
+var (
+	ErrRateNotFound   = errors.New("exchange rate not available")
+	ErrAmountTooSmall = errors.New("converted amount is too small")
+)
+
+// Rate is the mid-market rate between two currencies, in units of To per
+// unit of From, and the spread taken off it.
+type Rate struct {
+	From   string
+	To     string
+	Mid    *big.Rat
+	Spread *big.Rat
+}
+
+// RateProvider looks up exchange rates. Implementations must be safe for
+// concurrent use.
+type RateProvider interface {
+	Rate(ctx context.Context, from string, to string) (Rate, error)
+}
+
+// Conversion is the result of converting an amount with a Rate.
+type Conversion struct {
+	FromCurrency string
+	ToCurrency   string
+	Amount       int64
+	ToAmount     int64
+	// Rate is the applied rate after the spread, rounded to RateScale.
+	Rate string
+	// Spread is the fraction taken off the mid-market rate, rounded to SpreadScale.
+	Spread string
+}
+
+// Applied returns the rate the client gets, rounded to RateScale so that the
+// stored rate reproduces the converted amount exactly.
+func (rate Rate) Applied() *big.Rat {
+	applied := new(big.Rat).Sub(big.NewRat(1, 1), rate.Spread)
+	applied.Mul(applied, rate.Mid)
+
+	rounded, _ := new(big.Rat).SetString(applied.FloatString(RateScale))
+	return rounded
+}
+
+// Convert converts amount, rounding the result down to whole units.
+func (rate Rate) Convert(amount int64) (Conversion, error) {
+	return rate.ConvertMinorUnits(amount, 0, 0)
+}
+
+// ConvertMinorUnits converts amount, given in minor units of From with
+// fromUnit decimal places, into minor units of To with toUnit decimal places.
+// The rate itself is quoted per major unit. The result is rounded down.
+func (rate Rate) ConvertMinorUnits(amount int64, fromUnit int, toUnit int) (Conversion, error) {
+	applied := rate.Applied()
+
+	converted := new(big.Int).Mul(big.NewInt(amount), applied.Num())
+	divisor := new(big.Int).Set(applied.Denom())
+	if toUnit > fromUnit {
+		converted.Mul(converted, pow10(toUnit-fromUnit))
+	} else if fromUnit > toUnit {
+		divisor.Mul(divisor, pow10(fromUnit-toUnit))
+	}
+	converted.Quo(converted, divisor)
+
+	if !converted.IsInt64() {
+		return Conversion{}, fmt.Errorf("converted amount overflows: %s", converted)
+	}
+	if converted.Sign() <= 0 {
+		return Conversion{}, ErrAmountTooSmall
+	}
+
+	return Conversion{
+		FromCurrency: rate.From,
+		ToCurrency:   rate.To,
+		Amount:       amount,
+		ToAmount:     converted.Int64(),
+		Rate:         applied.FloatString(RateScale),
+		Spread:       rate.Spread.FloatString(SpreadScale),
+	}, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Convert looks up the rate from provider and converts amount with it,
+// taking the minor units of both currencies from util.Currencies.
+func Convert(ctx context.Context, provider RateProvider, from string, to string, amount int64) (Conversion, error) {
+	rate, err := provider.Rate(ctx, from, to)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	fromCurrency, ok := util.Currencies.Get(from)
+	if !ok {
+		return Conversion{}, fmt.Errorf("unknown currency: %s", from)
+	}
+	toCurrency, ok := util.Currencies.Get(to)
+	if !ok {
+		return Conversion{}, fmt.Errorf("unknown currency: %s", to)
+	}
+
+	return rate.ConvertMinorUnits(amount, fromCurrency.MinorUnit, toCurrency.MinorUnit)
+}
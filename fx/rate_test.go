@@ -0,0 +1,78 @@
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func TestConvert(t *testing.T) {
+	provider, err := LoadStaticRateProvider("testdata/rates.json", "0.01")
+	require.NoError(t, err)
+
+	conversion, err := Convert(context.Background(), provider, util.USD, util.IDR, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(1534500), conversion.ToAmount)
+	require.Equal(t, "15345.000000000000", conversion.Rate)
+	require.Equal(t, "0.010000", conversion.Spread)
+
+	// The inverse rate is derived and the result rounded down.
+	conversion, err = Convert(context.Background(), provider, util.IDR, util.USD, 1000000)
+	require.NoError(t, err)
+	require.Equal(t, int64(63), conversion.ToAmount)
+
+	_, err = Convert(context.Background(), provider, util.IDR, util.USD, 10)
+	require.ErrorIs(t, err, ErrAmountTooSmall)
+
+	_, err = Convert(context.Background(), provider, util.USD, "SGD", 10)
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestConvertMinorUnits(t *testing.T) {
+	provider, err := LoadStaticRateProvider("testdata/rates.json", "0")
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), util.USD, util.IDR)
+	require.NoError(t, err)
+
+	// 1.00 USD is 15500 IDR whether IDR amounts carry decimals or not.
+	conversion, err := rate.ConvertMinorUnits(100, 2, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(15500), conversion.ToAmount)
+
+	conversion, err = rate.ConvertMinorUnits(100, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(1550000), conversion.ToAmount)
+
+	rate, err = provider.Rate(context.Background(), util.IDR, util.USD)
+	require.NoError(t, err)
+
+	// The derived rate is rounded to RateScale, so the result falls just
+	// short of 1.00 USD and is rounded down.
+	conversion, err = rate.ConvertMinorUnits(15500, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(99), conversion.ToAmount)
+}
+
+func TestConvertSameCurrency(t *testing.T) {
+	provider, err := NewStaticRateProvider(nil, "0.01")
+	require.NoError(t, err)
+
+	conversion, err := Convert(context.Background(), provider, util.USD, util.USD, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), conversion.ToAmount)
+	require.Equal(t, "0.000000", conversion.Spread)
+}
+
+func TestNewStaticRateProviderInvalid(t *testing.T) {
+	_, err := NewStaticRateProvider(map[string]string{"USDIDR": "15500"}, "0")
+	require.Error(t, err)
+
+	_, err = NewStaticRateProvider(map[string]string{"USD/IDR": "-1"}, "0")
+	require.Error(t, err)
+
+	_, err = NewStaticRateProvider(nil, "1")
+	require.Error(t, err)
+}
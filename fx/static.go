@@ -0,0 +1,95 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/superjantung/bankita-api/util"
+)
+
+// StaticRateProvider serves a fixed set of rates, keyed by currency pair such
+// as "USD/IDR". The inverse of a pair is derived when it is not listed.
+type StaticRateProvider struct {
+	rates  map[string]*big.Rat
+	spread *big.Rat
+}
+
+var _ RateProvider = (*StaticRateProvider)(nil)
+
+func NewStaticRateProvider(rates map[string]string, spread string) (*StaticRateProvider, error) {
+	spreadRat, ok := new(big.Rat).SetString(spread)
+	if !ok || spreadRat.Sign() < 0 || spreadRat.Cmp(big.NewRat(1, 1)) >= 0 {
+		return nil, fmt.Errorf("invalid spread: %q", spread)
+	}
+
+	provider := &StaticRateProvider{
+		rates:  make(map[string]*big.Rat, len(rates)),
+		spread: spreadRat,
+	}
+
+	for pair, value := range rates {
+		currencies := strings.Split(pair, "/")
+		if len(currencies) != 2 {
+			return nil, fmt.Errorf("invalid currency pair: %q", pair)
+		}
+
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid rate for %s: %q", pair, value)
+		}
+		provider.rates[pair] = rate
+	}
+
+	return provider, nil
+}
+
+// LoadStaticRateProvider reads rates from a JSON file such as
+// {"USD/IDR": "15500"}.
+func LoadStaticRateProvider(path string, spread string) (*StaticRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+
+	var rates map[string]string
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %w", err)
+	}
+
+	return NewStaticRateProvider(rates, spread)
+}
+
+// NewRateProvider builds the provider configured by FX_RATES_FILE. Without a
+// rates file only same-currency rates are available.
+func NewRateProvider(config util.Config) (RateProvider, error) {
+	spread := config.FXSpread
+	if spread == "" {
+		spread = "0"
+	}
+
+	if config.FXRatesFile == "" {
+		return NewStaticRateProvider(nil, spread)
+	}
+	return LoadStaticRateProvider(config.FXRatesFile, spread)
+}
+
+func (provider *StaticRateProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	if from == to {
+		return Rate{From: from, To: to, Mid: big.NewRat(1, 1), Spread: new(big.Rat)}, nil
+	}
+
+	mid, ok := provider.rates[from+"/"+to]
+	if !ok {
+		inverse, ok := provider.rates[to+"/"+from]
+		if !ok {
+			return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+		}
+		mid = new(big.Rat).Inv(inverse)
+	}
+
+	return Rate{From: from, To: to, Mid: mid, Spread: provider.spread}, nil
+}
@@ -0,0 +1,157 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+)
+
+// publicMethods lists the RPCs that can be called without an access token.
+// Every other method of the Bankita service requires authentication.
+// Reflection is public so that clients such as evans can list the services.
+var publicMethods = map[string]bool{
+	pb.Bankita_CreateUser_FullMethodName:                                     true,
+	pb.Bankita_LoginUser_FullMethodName:                                      true,
+	pb.Bankita_RenewAccessToken_FullMethodName:                               true,
+	healthpb.Health_Check_FullMethodName:                                     true,
+	healthpb.Health_Watch_FullMethodName:                                     true,
+	reflectionpb.ServerReflection_ServerReflectionInfo_FullMethodName:        true,
+	reflectionv1alphapb.ServerReflection_ServerReflectionInfo_FullMethodName: true,
+}
+
+type authPayloadKey struct{}
+
+func (server *Server) UnaryAuthInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := server.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (server *Server) StreamAuthInterceptor(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := server.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// contextStream replaces the context of a server stream, which interceptors
+// cannot do otherwise.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (server *Server) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if publicMethods[fullMethod] {
+		return ctx, nil
+	}
+
+	payload, err := server.verifyAccessToken(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
+	}
+
+	err = server.checkSession(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	setLogUsername(ctx, payload.Username)
+	return context.WithValue(ctx, authPayloadKey{}, payload), nil
+}
+
+// checkSession rejects an access token whose session has been blocked by a
+// logout or a revocation, or that was issued without a session.
+func (server *Server) checkSession(ctx context.Context, payload *token.Payload) error {
+	if payload.SessionID == uuid.Nil {
+		return status.Errorf(codes.Unauthenticated, "unauthorized: access token has no session")
+	}
+
+	session, err := server.store.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return status.Errorf(codes.Unauthenticated, "unauthorized: session not found")
+		}
+		return status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if session.IsBlocked {
+		return status.Errorf(codes.Unauthenticated, "unauthorized: blocked session")
+	}
+	return nil
+}
+
+func (server *Server) verifyAccessToken(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing authorization header")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != authorizationBearer {
+		return nil, fmt.Errorf("unsupported authorization type: %s", authType)
+	}
+
+	accessToken := fields[1]
+	payload, err := server.tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %s", err)
+	}
+
+	return payload, nil
+}
+
+// authorizeUser returns the payload stored in the context by the auth
+// interceptors, failing when the RPC was reached without authentication.
+func authorizeUser(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(authPayloadKey{}).(*token.Payload)
+	if !ok || payload == nil {
+		return nil, status.Errorf(codes.Unauthenticated, "unauthorized: missing authentication payload")
+	}
+	return payload, nil
+}
@@ -0,0 +1,304 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// authSessionID is the session of the access tokens created by
+// newContextWithBearerToken.
+var authSessionID = uuid.New()
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, authorizationType string, username string, duration time.Duration) context.Context {
+	accessToken, payload, err := tokenMaker.CreateAccessToken(username, authSessionID, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	return newContextWithToken(authorizationType, accessToken)
+}
+
+// stubAuthSession lets the auth interceptors find the active session of the
+// access tokens created by newContextWithBearerToken.
+func stubAuthSession(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(authSessionID)).
+		AnyTimes().
+		Return(db.Session{ID: authSessionID}, nil)
+}
+
+func newContextWithToken(authorizationType string, token string) context.Context {
+	md := metadata.MD{
+		authorizationHeader: []string{fmt.Sprintf("%s %s", authorizationType, token)},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	testCases := []struct {
+		name       string
+		fullMethod string
+		buildCtx   func(t *testing.T, tokenMaker token.Maker) context.Context
+		check      func(t *testing.T, payload *token.Payload, err error)
+	}{
+		{
+			name:       "OK",
+			fullMethod: pb.Bankita_GetAccount_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, authorizationBearer, "user", time.Minute)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "user", payload.Username)
+			},
+		},
+		{
+			name:       "PublicMethod",
+			fullMethod: pb.Bankita_LoginUser_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payload)
+			},
+		},
+		{
+			name:       "HealthCheck",
+			fullMethod: healthpb.Health_Check_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payload)
+			},
+		},
+		{
+			name:       "NoAuthorization",
+			fullMethod: pb.Bankita_GetAccount_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return metadata.NewIncomingContext(context.Background(), metadata.MD{})
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:       "UnsupportedAuthorization",
+			fullMethod: pb.Bankita_GetAccount_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "unsupported", "user", time.Minute)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:       "ExpiredToken",
+			fullMethod: pb.Bankita_GetAccount_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, authorizationBearer, "user", -time.Minute)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:       "RefreshToken",
+			fullMethod: pb.Bankita_GetAccount_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				refreshToken, _, err := tokenMaker.CreateRefreshToken("user", time.Minute)
+				require.NoError(t, err)
+				return newContextWithToken(authorizationBearer, refreshToken)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:       "NoSession",
+			fullMethod: pb.Bankita_GetAccount_FullMethodName,
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, _, err := tokenMaker.CreateAccessToken("user", uuid.Nil, time.Minute)
+				require.NoError(t, err)
+				return newContextWithToken(authorizationBearer, accessToken)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubAuthSession(store)
+
+			server := newTestServer(t, store)
+			ctx := tc.buildCtx(t, server.tokenMaker)
+
+			info := &grpc.UnaryServerInfo{FullMethod: tc.fullMethod}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				payload, _ := ctx.Value(authPayloadKey{}).(*token.Payload)
+				return payload, nil
+			}
+
+			rsp, err := server.UnaryAuthInterceptor(ctx, nil, info, handler)
+			payload, _ := rsp.(*token.Payload)
+			tc.check(t, payload, err)
+		})
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (stream *testServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *testServerStream) SetHeader(md metadata.MD) error {
+	stream.header = metadata.Join(stream.header, md)
+	return nil
+}
+
+func TestStreamAuthInterceptor(t *testing.T) {
+	testCases := []struct {
+		name       string
+		fullMethod string
+		code       codes.Code
+	}{
+		{
+			name:       "Reflection",
+			fullMethod: reflectionpb.ServerReflection_ServerReflectionInfo_FullMethodName,
+			code:       codes.OK,
+		},
+		{
+			name:       "ReflectionV1Alpha",
+			fullMethod: reflectionv1alphapb.ServerReflection_ServerReflectionInfo_FullMethodName,
+			code:       codes.OK,
+		},
+		{
+			name:       "HealthWatch",
+			fullMethod: healthpb.Health_Watch_FullMethodName,
+			code:       codes.OK,
+		},
+		{
+			name:       "NoAuthorization",
+			fullMethod: pb.Bankita_GetAccount_FullMethodName,
+			code:       codes.Unauthenticated,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			stream := &testServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{})}
+
+			info := &grpc.StreamServerInfo{FullMethod: tc.fullMethod, IsServerStream: true}
+			handler := func(srv interface{}, stream grpc.ServerStream) error {
+				return nil
+			}
+
+			err := server.StreamAuthInterceptor(nil, stream, info, handler)
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestAuthenticateSession(t *testing.T) {
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(db.Session{ID: sessionID}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			// Logging out or revoking a session blocks it.
+			name: "RevokedSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(db.Session{ID: sessionID, IsBlocked: true}, nil)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(sessionID)).Times(1).Return(db.Session{}, sql.ErrConnDone)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			accessToken, _, err := server.tokenMaker.CreateAccessToken("user", sessionID, time.Minute)
+			require.NoError(t, err)
+
+			md := metadata.MD{
+				authorizationHeader: []string{fmt.Sprintf("%s %s", authorizationBearer, accessToken)},
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			info := &grpc.UnaryServerInfo{FullMethod: pb.Bankita_GetAccount_FullMethodName}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			}
+
+			_, err = server.UnaryAuthInterceptor(ctx, nil, info, handler)
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package gapi
 import (
 	db "github.com/superjantung/bankita-api/db/sqlc"
 	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -15,3 +16,94 @@ func convertUser(user db.User) *pb.User {
 		CreatedAt:         timestamppb.New(user.CreatedAt),
 	}
 }
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:             account.ID,
+		Owner:          account.Owner,
+		Balance:        account.Balance,
+		Currency:       account.Currency,
+		CreatedAt:      timestamppb.New(account.CreatedAt),
+		OverdraftLimit: account.OverdraftLimit,
+	}
+}
+
+func convertAccounts(accounts []db.Account) []*pb.Account {
+	rsp := make([]*pb.Account, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = convertAccount(account)
+	}
+	return rsp
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	rsp := &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+		ToAmount:      transfer.ToAmount,
+		ExchangeRate:  transfer.ExchangeRate,
+		Spread:        transfer.Spread,
+		SpreadAmount:  transfer.SpreadAmount,
+	}
+	if transfer.QuoteID.Valid {
+		rsp.QuoteId = transfer.QuoteID.UUID.String()
+	}
+	return rsp
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:         entry.ID,
+		AccountId:  entry.AccountID,
+		Amount:     entry.Amount,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+		TransferId: entry.TransferID.Int64,
+	}
+}
+
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+		CreatedAt: timestamppb.New(session.CreatedAt),
+	}
+}
+
+func convertFxQuote(quote db.FxQuote) *pb.FxQuote {
+	return &pb.FxQuote{
+		Id:           quote.ID.String(),
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		Amount:       quote.Amount,
+		ToAmount:     quote.ToAmount,
+		ExchangeRate: quote.ExchangeRate,
+		Spread:       quote.Spread,
+		ExpiresAt:    timestamppb.New(quote.ExpiresAt),
+		CreatedAt:    timestamppb.New(quote.CreatedAt),
+	}
+}
+
+func convertMoney(money util.Money) *pb.Money {
+	return &pb.Money{
+		Amount:   money.Decimal(),
+		Currency: money.Currency,
+	}
+}
+
+func convertStatementEntries(entries []db.StatementEntry) []*pb.StatementEntry {
+	rsp := make([]*pb.StatementEntry, len(entries))
+	for i, entry := range entries {
+		rsp[i] = &pb.StatementEntry{
+			Entry:                 convertEntry(entry.Entry),
+			Balance:               entry.Balance,
+			CounterpartyAccountId: entry.CounterpartyAccountID,
+			CounterpartyOwner:     entry.CounterpartyOwner,
+		}
+	}
+	return rsp
+}
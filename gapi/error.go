@@ -0,0 +1,44 @@
+package gapi
+
+import (
+	"errors"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storeError maps an error returned by the store to a gRPC status error.
+func storeError(err error, msg string) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		code = codes.NotFound
+	case errors.Is(err, db.ErrUniqueViolation):
+		code = codes.AlreadyExists
+	case errors.Is(err, db.ErrForeignKeyViolation), errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrSettlementAccountNotFound), errors.Is(err, db.ErrQuoteExpired),
+		errors.Is(err, db.ErrQuoteUsed):
+		code = codes.FailedPrecondition
+	case errors.Is(err, db.ErrIdempotencyKeyMismatch), errors.Is(err, db.ErrQuoteMismatch),
+		errors.Is(err, util.ErrCurrencyMismatch), errors.Is(err, util.ErrAmountOverflow):
+		code = codes.InvalidArgument
+	case errors.Is(err, db.ErrSerialization):
+		code = codes.Aborted
+	}
+	return status.Errorf(code, "%s: %s", msg, err)
+}
+
+// exchangeError maps an error from a currency conversion to a gRPC status error.
+func exchangeError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, fx.ErrRateNotFound):
+		code = codes.FailedPrecondition
+	case errors.Is(err, fx.ErrAmountTooSmall):
+		code = codes.InvalidArgument
+	}
+	return status.Errorf(code, "failed to convert amount: %s", err)
+}
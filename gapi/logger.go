@@ -0,0 +1,153 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	requestIDHeader         = "x-request-id"
+	authenticatedUserHeader = "x-authenticated-user"
+)
+
+// requestLogFields collects values discovered while handling a request, such
+// as the authenticated username, so the logger can report them afterwards.
+type requestLogFields struct {
+	username string
+	err      error
+}
+
+type requestLogFieldsKey struct{}
+
+// setLogUsername records the authenticated username for GrpcLogger and sends
+// it back in a response header, from which the gateway logs it as well.
+func setLogUsername(ctx context.Context, username string) {
+	if fields, ok := ctx.Value(requestLogFieldsKey{}).(*requestLogFields); ok {
+		fields.username = username
+	}
+	// Fails only outside of a gRPC call, e.g. in tests.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(authenticatedUserHeader, username))
+}
+
+// GrpcLogger logs every unary RPC. It must run before the auth interceptor
+// so that unauthenticated calls are logged too.
+func GrpcLogger(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	startTime := time.Now()
+	fields := &requestLogFields{}
+	ctx = context.WithValue(ctx, requestLogFieldsKey{}, fields)
+
+	result, err := handler(ctx, req)
+	duration := time.Since(startTime)
+
+	statusCode := status.Code(err)
+
+	logger := log.Info()
+	if err != nil {
+		logger = log.Error().Err(err)
+	}
+
+	logger.Str("protocol", "grpc").
+		Str("method", info.FullMethod).
+		Int("status_code", int(statusCode)).
+		Str("status_text", statusCode.String()).
+		Dur("duration", duration).
+		Str("request_id", grpcRequestID(ctx)).
+		Str("username", fields.username).
+		Msg("received a gRPC request")
+
+	return result, err
+}
+
+func grpcRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDHeader); len(ids) > 0 {
+			return ids[0]
+		}
+	}
+	return ""
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	StatusCode int
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	rec.StatusCode = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// HttpLogger logs every HTTP request served by the gateway. Response bodies
+// are never logged since they carry personal data. The username and the
+// error come from the gRPC server through GatewayForwardResponse and
+// GatewayErrorHandler.
+func HttpLogger(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		startTime := time.Now()
+		fields := &requestLogFields{}
+		req = req.WithContext(context.WithValue(req.Context(), requestLogFieldsKey{}, fields))
+		rec := &responseRecorder{
+			ResponseWriter: res,
+			StatusCode:     http.StatusOK,
+		}
+		handler.ServeHTTP(rec, req)
+		duration := time.Since(startTime)
+
+		logger := log.Info()
+		if rec.StatusCode >= http.StatusBadRequest {
+			logger = log.Error().Err(fields.err)
+		}
+
+		logger.Str("protocol", "http").
+			Str("method", req.Method).
+			Str("path", req.RequestURI).
+			Int("status_code", rec.StatusCode).
+			Str("status_text", http.StatusText(rec.StatusCode)).
+			Dur("duration", duration).
+			Str("request_id", req.Header.Get(requestIDHeader)).
+			Str("username", fields.username).
+			Msg("received a HTTP request")
+	})
+}
+
+// GatewayForwardResponse is a grpc-gateway forward response option that
+// hands the username reported by the gRPC server to HttpLogger.
+func GatewayForwardResponse(ctx context.Context, _ http.ResponseWriter, _ proto.Message) error {
+	setGatewayLogUsername(ctx)
+	return nil
+}
+
+// GatewayErrorHandler hands the error returned by the gRPC server to
+// HttpLogger and writes it with the request ID in the body.
+func GatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if fields, ok := ctx.Value(requestLogFieldsKey{}).(*requestLogFields); ok {
+		fields.err = err
+	}
+	setGatewayLogUsername(ctx)
+	writeGatewayError(ctx, mux, marshaler, w, r, err)
+}
+
+func setGatewayLogUsername(ctx context.Context) {
+	md, ok := runtime.ServerMetadataFromContext(ctx)
+	if !ok {
+		return
+	}
+	if usernames := md.HeaderMD.Get(authenticatedUserHeader); len(usernames) > 0 {
+		if fields, ok := ctx.Value(requestLogFieldsKey{}).(*requestLogFields); ok {
+			fields.username = usernames[0]
+		}
+	}
+}
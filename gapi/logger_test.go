@@ -0,0 +1,88 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	"github.com/superjantung/bankita-api/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = logger }()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	stubAuthSession(store)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationBearer, "user", time.Minute)
+	md, _ := metadata.FromIncomingContext(ctx)
+	md.Set(requestIDHeader, "request-id")
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Bankita_GetAccount_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.UnaryAuthInterceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+	}
+
+	rsp, err := GrpcLogger(ctx, nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", rsp)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, pb.Bankita_GetAccount_FullMethodName, entry["method"])
+	require.Equal(t, float64(codes.OK), entry["status_code"])
+	require.Equal(t, "request-id", entry["request_id"])
+	require.Equal(t, "user", entry["username"])
+}
+
+func TestHttpLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = logger }()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md := runtime.ServerMetadata{HeaderMD: metadata.Pairs(authenticatedUserHeader, "user")}
+		ctx := runtime.NewServerMetadataContext(r.Context(), md)
+		err := status.Error(codes.NotFound, "account not found")
+		GatewayErrorHandler(ctx, runtime.NewServeMux(), &runtime.JSONPb{}, w, r, err)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/accounts/1", nil)
+	request.Header.Set(requestIDHeader, "request-id")
+	recorder := httptest.NewRecorder()
+	HttpLogger(handler).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, float64(http.StatusNotFound), entry["status_code"])
+	require.Equal(t, "request-id", entry["request_id"])
+	require.Equal(t, "user", entry["username"])
+	require.Contains(t, entry["error"], "account not found")
+	require.NotContains(t, entry, "body")
+}
@@ -0,0 +1,63 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/token"
+	"github.com/superjantung/bankita-api/util"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		IdempotencyKeyTTL:    time.Hour,
+		FXRatesFile:          "../fx/testdata/rates.json",
+		FXSpread:             "0.01",
+		FXQuoteTTL:           time.Minute,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	return server
+}
+
+// newContextWithAuthPayload returns the context a handler sees after the auth
+// interceptor accepted an access token of the user.
+func newContextWithAuthPayload(t *testing.T, username string) context.Context {
+	payload, err := token.NewPayload(username, token.TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	return context.WithValue(context.Background(), authPayloadKey{}, payload)
+}
+
+func randomAccount(owner string, currency string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt64(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomBalance(),
+		Currency: currency,
+	}
+}
+
+// randomSession returns an active session of the user started with the
+// returned refresh token.
+func randomSession(t *testing.T, tokenMaker token.Maker, username string) (db.Session, string) {
+	refreshToken, payload, err := tokenMaker.CreateRefreshToken(username, time.Hour)
+	require.NoError(t, err)
+
+	session := db.Session{
+		ID:           payload.ID,
+		FamilyID:     payload.ID,
+		Username:     username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+	return session, refreshToken
+}
@@ -0,0 +1,72 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
+	userAgentHeader            = "user-agent"
+	xForwardedForHeader        = "x-forwarded-for"
+	idempotencyKeyHeader       = "idempotency-key"
+	maxIdempotencyKeyLength    = 255
+)
+
+type Metadata struct {
+	UserAgent string
+	ClientIP  string
+}
+
+// extractMetadata collects client information from the incoming request.
+// Calls proxied by grpc-gateway carry the original HTTP user agent and client
+// address in forwarded headers, which take precedence over the gRPC peer.
+// X-Forwarded-For is only read when the transport peer is one of the trusted
+// proxies, since any direct gRPC client can set the gateway headers itself,
+// and only its right-most entry: that is the address the proxy saw, while
+// anything to the left of it was supplied by the client.
+func extractMetadata(ctx context.Context, trustedProxies util.TrustedProxies) *Metadata {
+	mtdt := &Metadata{}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		mtdt.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(mtdt.ClientIP); err == nil {
+			mtdt.ClientIP = host
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
+			mtdt.UserAgent = userAgents[0]
+		} else if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+			mtdt.UserAgent = userAgents[0]
+		}
+
+		if trustedProxies.Contains(mtdt.ClientIP) {
+			if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
+				hops := strings.Split(clientIPs[len(clientIPs)-1], ",")
+				if hop := strings.TrimSpace(hops[len(hops)-1]); hop != "" {
+					mtdt.ClientIP = hop
+				}
+			}
+		}
+	}
+
+	return mtdt
+}
+
+// extractIdempotencyKey returns the client supplied idempotency key, if any.
+// The gateway forwards the Idempotency-Key HTTP header under the same name.
+func extractIdempotencyKey(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(idempotencyKeyHeader); len(keys) > 0 {
+			return keys[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	trustedProxies, err := util.ParseTrustedProxies("127.0.0.1,::1")
+	require.NoError(t, err)
+
+	directPeer := &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.5"), Port: 51234},
+	}
+	gatewayPeer := &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 51234},
+	}
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected Metadata
+	}{
+		{
+			name: "DirectGRPC",
+			ctx: peer.NewContext(
+				metadata.NewIncomingContext(context.Background(), metadata.Pairs(userAgentHeader, "grpc-go/1.58.0")),
+				directPeer,
+			),
+			expected: Metadata{UserAgent: "grpc-go/1.58.0", ClientIP: "203.0.113.5"},
+		},
+		{
+			name: "Gateway",
+			ctx: peer.NewContext(
+				metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+					userAgentHeader, "grpc-go/1.58.0",
+					grpcGatewayUserAgentHeader, "Mozilla/5.0",
+					xForwardedForHeader, "198.51.100.7, 10.0.0.1",
+				)),
+				gatewayPeer,
+			),
+			expected: Metadata{UserAgent: "Mozilla/5.0", ClientIP: "10.0.0.1"},
+		},
+		{
+			name: "DirectGRPCForwardedFor",
+			ctx: peer.NewContext(
+				metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+					userAgentHeader, "grpc-go/1.58.0",
+					xForwardedForHeader, "198.51.100.7",
+				)),
+				directPeer,
+			),
+			expected: Metadata{UserAgent: "grpc-go/1.58.0", ClientIP: "203.0.113.5"},
+		},
+		{
+			name: "SpoofedGatewayHeaders",
+			ctx: peer.NewContext(
+				metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+					userAgentHeader, "grpc-go/1.58.0",
+					grpcGatewayUserAgentHeader, "Mozilla/5.0",
+					xForwardedForHeader, "198.51.100.7",
+				)),
+				directPeer,
+			),
+			expected: Metadata{UserAgent: "Mozilla/5.0", ClientIP: "203.0.113.5"},
+		},
+		{
+			name:     "NoMetadata",
+			ctx:      context.Background(),
+			expected: Metadata{},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, *extractMetadata(tc.ctx, trustedProxies))
+		})
+	}
+}
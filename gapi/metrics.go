@@ -0,0 +1,40 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bankita_grpc_requests_total",
+		Help: "Number of unary RPCs handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bankita_grpc_request_duration_seconds",
+		Help:    "Latency of unary RPCs, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// GrpcMetrics records the request count and latency of every unary RPC.
+func GrpcMetrics(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	startTime := time.Now()
+	result, err := handler(ctx, req)
+
+	grpcRequestDuration.WithLabelValues(info.FullMethod).Observe(time.Since(startTime).Seconds())
+	grpcRequestsTotal.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+
+	return result, err
+}
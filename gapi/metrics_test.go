@@ -0,0 +1,28 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcMetrics(t *testing.T) {
+	method := pb.Bankita_GetAccount_FullMethodName
+	counter := grpcRequestsTotal.WithLabelValues(method, codes.NotFound.String())
+	before := testutil.ToFloat64(counter)
+
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "account not found")
+	}
+
+	_, err := GrpcMetrics(context.Background(), nil, info, handler)
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, before+1, testutil.ToFloat64(counter))
+}
@@ -0,0 +1,120 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog/log"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GrpcRequestID makes sure every RPC has a request ID. The ID is written back
+// to the incoming metadata for the handlers, attached to the request logger,
+// sent to the client as a response header and added to error details.
+func GrpcRequestID(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, requestID := newRequestIDContext(ctx)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID)); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("cannot send request id header")
+	}
+
+	result, err := handler(ctx, req)
+	if err != nil {
+		err = withRequestID(err, requestID)
+	}
+	return result, err
+}
+
+// GrpcStreamRequestID does for streaming RPCs what GrpcRequestID does for
+// unary ones.
+func GrpcStreamRequestID(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, requestID := newRequestIDContext(stream.Context())
+	if err := stream.SetHeader(metadata.Pairs(requestIDHeader, requestID)); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("cannot send request id header")
+	}
+
+	err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	if err != nil {
+		err = withRequestID(err, requestID)
+	}
+	return err
+}
+
+// newRequestIDContext writes the request ID back to the incoming metadata
+// and attaches a logger carrying it.
+func newRequestIDContext(ctx context.Context) (context.Context, string) {
+	requestID := util.RequestID(grpcRequestID(ctx))
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set(requestIDHeader, requestID)
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	logger := log.With().Str("request_id", requestID).Logger()
+	return logger.WithContext(ctx), requestID
+}
+
+func withRequestID(err error, requestID string) error {
+	st, err2 := status.Convert(err).WithDetails(&errdetails.RequestInfo{RequestId: requestID})
+	if err2 != nil {
+		return err
+	}
+	return st.Err()
+}
+
+// HttpRequestID accepts the client X-Request-ID or generates one before the
+// gateway forwards the request, and echoes it in the response.
+func HttpRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requestID := util.RequestID(req.Header.Get(util.RequestIDHeader))
+		req.Header.Set(util.RequestIDHeader, requestID)
+		res.Header().Set(util.RequestIDHeader, requestID)
+
+		logger := log.With().Str("request_id", requestID).Logger()
+		handler.ServeHTTP(res, req.WithContext(logger.WithContext(req.Context())))
+	})
+}
+
+// writeGatewayError writes err like the default grpc-gateway error handler
+// and adds the request ID at the top level of the JSON body, as the Gin
+// server does in its error responses.
+func writeGatewayError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	rec := &errorBodyRecorder{ResponseWriter: w}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, rec, r, err)
+
+	body := map[string]json.RawMessage{}
+	if err := json.Unmarshal(rec.body.Bytes(), &body); err != nil {
+		w.Write(rec.body.Bytes())
+		return
+	}
+
+	body["request_id"], _ = json.Marshal(r.Header.Get(util.RequestIDHeader))
+	json.NewEncoder(w).Encode(body)
+}
+
+// errorBodyRecorder passes headers and the status code through and holds the
+// body back so that it can be rewritten.
+type errorBodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (rec *errorBodyRecorder) Write(body []byte) (int, error) {
+	return rec.body.Write(body)
+}
@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcRequestID(t *testing.T) {
+	md := metadata.MD{requestIDHeader: []string{"client-request-id"}}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Bankita_GetAccount_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		require.Equal(t, "client-request-id", grpcRequestID(ctx))
+		return nil, status.Error(codes.NotFound, "account not found")
+	}
+
+	_, err := GrpcRequestID(ctx, nil, info, handler)
+	st := status.Convert(err)
+	require.Equal(t, codes.NotFound, st.Code())
+	require.Len(t, st.Details(), 1)
+
+	requestInfo, ok := st.Details()[0].(*errdetails.RequestInfo)
+	require.True(t, ok)
+	require.Equal(t, "client-request-id", requestInfo.GetRequestId())
+}
+
+func TestGrpcRequestIDGenerated(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Bankita_GetAccount_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return grpcRequestID(ctx), nil
+	}
+
+	rsp, err := GrpcRequestID(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	require.NotEmpty(t, rsp)
+}
+
+func TestGrpcStreamRequestID(t *testing.T) {
+	md := metadata.MD{requestIDHeader: []string{"client-request-id"}}
+	stream := &testServerStream{ctx: metadata.NewIncomingContext(context.Background(), md)}
+
+	info := &grpc.StreamServerInfo{FullMethod: healthpb.Health_Watch_FullMethodName, IsServerStream: true}
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		require.Equal(t, "client-request-id", grpcRequestID(stream.Context()))
+		return status.Error(codes.Unavailable, "shutting down")
+	}
+
+	err := GrpcStreamRequestID(nil, stream, info, handler)
+	require.Equal(t, []string{"client-request-id"}, stream.header.Get(requestIDHeader))
+
+	st := status.Convert(err)
+	require.Equal(t, codes.Unavailable, st.Code())
+	require.Len(t, st.Details(), 1)
+}
+
+func TestGatewayErrorRequestID(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/v1/accounts/1", nil)
+	request.Header.Set(util.RequestIDHeader, "client-request-id")
+	recorder := httptest.NewRecorder()
+
+	err := status.Error(codes.NotFound, "account not found")
+	GatewayErrorHandler(request.Context(), runtime.NewServeMux(), &runtime.JSONPb{}, recorder, request, err)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, "client-request-id", body["request_id"])
+	require.Equal(t, "account not found", body["message"])
+}
@@ -0,0 +1,269 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDepositAPI(t *testing.T) {
+	amount := int64(10)
+	operator := db.User{Username: util.RandomOwner(), Role: util.OperatorRole}
+	user := db.User{Username: util.RandomOwner(), Role: util.DepositorRole}
+	account := randomAccount(user.Username, util.USD)
+	settlement := randomAccount(db.SettlementAccountOwner, util.USD)
+
+	testCases := []struct {
+		name       string
+		req        *pb.DepositRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.DepositResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.DepositRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.DepositTxParams{AccountID: account.ID, Amount: amount}
+				result := db.DepositTxResult{Account: account, Entry: db.Entry{AccountID: account.ID, Amount: amount}}
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, rsp.GetAccount().GetId())
+				require.Equal(t, amount, rsp.GetEntry().GetAmount())
+			},
+		},
+		{
+			name: "InvalidAmount",
+			req:  &pb.DepositRequest{AccountId: account.ID, Amount: -amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			req:  &pb.DepositRequest{AccountId: account.ID, Amount: amount, Currency: util.IDR},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NotOperator",
+			req:  &pb.DepositRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "SettlementAccount",
+			req:  &pb.DepositRequest{AccountId: settlement.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(settlement.ID)).Times(1).Return(settlement, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "SettlementAccountNotFound",
+			req:  &pb.DepositRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DepositTxResult{}, db.ErrSettlementAccountNotFound)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.DepositRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DepositTxResult{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.DepositRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.DepositResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.Deposit(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
+
+func TestWithdrawAPI(t *testing.T) {
+	amount := int64(10)
+	operator := db.User{Username: util.RandomOwner(), Role: util.OperatorRole}
+	user := db.User{Username: util.RandomOwner(), Role: util.DepositorRole}
+	account := randomAccount(user.Username, util.USD)
+
+	testCases := []struct {
+		name       string
+		req        *pb.WithdrawRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.WithdrawResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.WithdrawRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.WithdrawTxParams{AccountID: account.ID, Amount: amount}
+				result := db.WithdrawTxResult{Account: account, Entry: db.Entry{AccountID: account.ID, Amount: -amount}}
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			check: func(t *testing.T, rsp *pb.WithdrawResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, rsp.GetAccount().GetId())
+				require.Equal(t, -amount, rsp.GetEntry().GetAmount())
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req:  &pb.WithdrawRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(1).Return(db.WithdrawTxResult{}, db.ErrInsufficientFunds)
+			},
+			check: func(t *testing.T, rsp *pb.WithdrawResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req:  &pb.WithdrawRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.WithdrawResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NotOperator",
+			req:  &pb.WithdrawRequest{AccountId: account.ID, Amount: amount, Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.WithdrawResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.Withdraw(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
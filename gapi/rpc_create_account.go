@@ -0,0 +1,45 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !util.IsSupportedCurrency(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", req.GetCurrency())
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    authPayload.Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	}
+
+	account, err := server.store.CreateAccount(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrUniqueViolation) {
+			return nil, status.Errorf(codes.AlreadyExists, "account with currency %s already exists: %s", req.GetCurrency(), err)
+		}
+		if errors.Is(err, db.ErrForeignKeyViolation) {
+			return nil, status.Errorf(codes.PermissionDenied, "account owner does not exist: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	rsp := &pb.CreateAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
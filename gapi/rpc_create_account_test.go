@@ -0,0 +1,133 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAccountAPI(t *testing.T) {
+	user := util.RandomOwner()
+	account := randomAccount(user, util.USD)
+	account.Balance = 0
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateAccountRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:    user,
+					Currency: util.USD,
+					Balance:  0,
+				}
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			check: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, rsp.GetAccount().GetId())
+				require.Equal(t, user, rsp.GetAccount().GetOwner())
+				require.Equal(t, util.USD, rsp.GetAccount().GetCurrency())
+			},
+		},
+		{
+			name: "UnsupportedCurrency",
+			req:  &pb.CreateAccountRequest{Currency: "XYZ"},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyExists",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrUniqueViolation)
+			},
+			check: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "OwnerNotFound",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrForeignKeyViolation)
+			},
+			check: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.CreateAccount(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateFxQuote(ctx context.Context, req *pb.CreateFxQuoteRequest) (*pb.CreateFxQuoteResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := quoteAmount(req)
+	if err != nil {
+		return nil, err
+	}
+	if !util.IsSupportedCurrency(req.GetToCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", req.GetToCurrency())
+	}
+	if amount.Currency == req.GetToCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "from and to currency must differ")
+	}
+
+	conversion, err := fx.Convert(ctx, server.rateProvider, amount.Currency, req.GetToCurrency(), amount.Amount)
+	if err != nil {
+		return nil, exchangeError(err)
+	}
+
+	arg := db.CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     authPayload.Username,
+		FromCurrency: conversion.FromCurrency,
+		ToCurrency:   conversion.ToCurrency,
+		Amount:       conversion.Amount,
+		ToAmount:     conversion.ToAmount,
+		ExchangeRate: conversion.Rate,
+		Spread:       conversion.Spread,
+		ExpiresAt:    time.Now().Add(server.config.FXQuoteTTL),
+	}
+
+	quote, err := server.store.CreateFxQuote(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "failed to create exchange quote")
+	}
+
+	rsp := &pb.CreateFxQuoteResponse{
+		Quote: convertFxQuote(quote),
+	}
+	return rsp, nil
+}
+
+// quoteAmount reads the amount to quote either from amount and from_currency,
+// in minor units, or from the decimal money field.
+func quoteAmount(req *pb.CreateFxQuoteRequest) (util.Money, error) {
+	amount := util.NewMoney(req.GetAmount(), req.GetFromCurrency())
+	if money := req.GetMoney(); money != nil {
+		if req.GetAmount() != 0 || (req.GetFromCurrency() != "" && req.GetFromCurrency() != money.GetCurrency()) {
+			return amount, status.Errorf(codes.InvalidArgument, "set either amount and from_currency or money")
+		}
+
+		var err error
+		amount, err = util.ParseMoney(money.GetAmount(), money.GetCurrency())
+		if err != nil {
+			return amount, status.Errorf(codes.InvalidArgument, "invalid money: %s", err)
+		}
+	}
+
+	if !util.IsSupportedCurrency(amount.Currency) {
+		return amount, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", amount.Currency)
+	}
+	if !amount.IsPositive() {
+		return amount, status.Errorf(codes.InvalidArgument, "amount must be greater than 0")
+	}
+	return amount, nil
+}
@@ -0,0 +1,250 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateFxQuoteAPI(t *testing.T) {
+	user := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateFxQuoteRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.USD,
+				ToCurrency:   util.IDR,
+				Amount:       100,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, user, arg.Username)
+						require.Equal(t, util.USD, arg.FromCurrency)
+						require.Equal(t, util.IDR, arg.ToCurrency)
+						require.Equal(t, int64(100), arg.Amount)
+						require.Positive(t, arg.ToAmount)
+						require.True(t, arg.ExpiresAt.After(time.Now()))
+						return newFxQuote(arg), nil
+					})
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(100), rsp.GetQuote().GetAmount())
+				require.Equal(t, util.IDR, rsp.GetQuote().GetToCurrency())
+				require.NotEmpty(t, rsp.GetQuote().GetId())
+			},
+		},
+		{
+			name: "DecimalMoney",
+			req: &pb.CreateFxQuoteRequest{
+				ToCurrency: util.IDR,
+				Money:      &pb.Money{Amount: "12.50", Currency: util.USD},
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, util.USD, arg.FromCurrency)
+						require.Equal(t, int64(1250), arg.Amount)
+						return newFxQuote(arg), nil
+					})
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(1250), rsp.GetQuote().GetAmount())
+			},
+		},
+		{
+			name: "AmountAndMoney",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.USD,
+				ToCurrency:   util.IDR,
+				Amount:       100,
+				Money:        &pb.Money{Amount: "1.00", Currency: util.USD},
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidMoney",
+			req: &pb.CreateFxQuoteRequest{
+				ToCurrency: util.IDR,
+				Money:      &pb.Money{Amount: "1.005", Currency: util.USD},
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "UnsupportedCurrency",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.USD,
+				ToCurrency:   "XYZ",
+				Amount:       100,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "SameCurrency",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.USD,
+				ToCurrency:   util.USD,
+				Amount:       100,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NonPositiveAmount",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.USD,
+				ToCurrency:   util.IDR,
+				Amount:       0,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "ConvertedAmountTooSmall",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.IDR,
+				ToCurrency:   util.USD,
+				Amount:       1,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), fx.ErrAmountTooSmall.Error())
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.USD,
+				ToCurrency:   util.IDR,
+				Amount:       100,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(db.FxQuote{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.CreateFxQuoteRequest{
+				FromCurrency: util.USD,
+				ToCurrency:   util.IDR,
+				Amount:       100,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateFxQuoteResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.CreateFxQuote(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
+
+func newFxQuote(arg db.CreateFxQuoteParams) db.FxQuote {
+	return db.FxQuote{
+		ID:           arg.ID,
+		Username:     arg.Username,
+		FromCurrency: arg.FromCurrency,
+		ToCurrency:   arg.ToCurrency,
+		Amount:       arg.Amount,
+		ToAmount:     arg.ToAmount,
+		ExchangeRate: arg.ExchangeRate,
+		Spread:       arg.Spread,
+		ExpiresAt:    arg.ExpiresAt,
+		CreatedAt:    time.Now(),
+	}
+}
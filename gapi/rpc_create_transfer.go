@@ -0,0 +1,190 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetFromAccountId() < 1 || req.GetToAccountId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id")
+	}
+	amount, err := transferAmount(req)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        amount,
+	}
+
+	if req.GetQuoteId() != "" {
+		quoteID, err := uuid.Parse(req.GetQuoteId())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid quote id: %s", err)
+		}
+		arg.QuoteID = &quoteID
+	}
+
+	if key := extractIdempotencyKey(ctx); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, status.Errorf(codes.InvalidArgument, "idempotency key must be at most %d characters", maxIdempotencyKeyLength)
+		}
+
+		arg.Idempotency = &db.IdempotencyParams{
+			Key:       key,
+			Username:  authPayload.Username,
+			ExpiresAt: time.Now().Add(server.config.IdempotencyKeyTTL),
+		}
+	}
+
+	toCurrency := req.GetToCurrency()
+	if toCurrency == "" && arg.QuoteID == nil {
+		toCurrency = amount.Currency
+	}
+
+	// A retry is answered from the recorded result, even if the quote has
+	// been used up or the exchange rate has moved since.
+	if arg.Idempotency != nil {
+		result, replayed, err := server.store.ReplayTransferTx(ctx, arg, toCurrency)
+		if err != nil {
+			return nil, storeError(err, "failed to create transfer")
+		}
+		if replayed {
+			return newTransferResponse(result), nil
+		}
+	}
+
+	if arg.QuoteID != nil {
+		quote, err := server.validQuote(ctx, *arg.QuoteID, authPayload.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		if toCurrency == "" {
+			toCurrency = quote.ToCurrency
+		}
+		if quote.FromCurrency != amount.Currency || quote.ToCurrency != toCurrency || quote.Amount != amount.Amount {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", db.ErrQuoteMismatch)
+		}
+	}
+	if !util.IsSupportedCurrency(toCurrency) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", toCurrency)
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account does not belong to the authenticated user")
+	}
+
+	_, err = server.validAccount(ctx, req.GetToAccountId(), toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.QuoteID == nil && toCurrency != amount.Currency {
+		conversion, err := fx.Convert(ctx, server.rateProvider, amount.Currency, toCurrency, amount.Amount)
+		if err != nil {
+			return nil, exchangeError(err)
+		}
+
+		arg.Exchange = &db.ExchangeParams{
+			ToAmount: util.NewMoney(conversion.ToAmount, toCurrency),
+			Rate:     conversion.Rate,
+			Spread:   conversion.Spread,
+		}
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "failed to create transfer")
+	}
+
+	return newTransferResponse(result), nil
+}
+
+func newTransferResponse(result db.TransferTxResult) *pb.CreateTransferResponse {
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+		Debited:     convertMoney(util.NewMoney(result.Transfer.Amount, result.FromAccount.Currency)),
+		Credited:    convertMoney(util.NewMoney(result.Transfer.ToAmount, result.ToAccount.Currency)),
+	}
+}
+
+// transferAmount reads the amount to transfer either from amount and
+// currency, in minor units, or from the decimal money field.
+func transferAmount(req *pb.CreateTransferRequest) (util.Money, error) {
+	amount := util.NewMoney(req.GetAmount(), req.GetCurrency())
+	if money := req.GetMoney(); money != nil {
+		if req.GetAmount() != 0 || (req.GetCurrency() != "" && req.GetCurrency() != money.GetCurrency()) {
+			return amount, status.Errorf(codes.InvalidArgument, "set either amount and currency or money")
+		}
+
+		var err error
+		amount, err = util.ParseMoney(money.GetAmount(), money.GetCurrency())
+		if err != nil {
+			return amount, status.Errorf(codes.InvalidArgument, "invalid money: %s", err)
+		}
+	}
+
+	if !util.IsSupportedCurrency(amount.Currency) {
+		return amount, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", amount.Currency)
+	}
+	if !amount.IsPositive() {
+		return amount, status.Errorf(codes.InvalidArgument, "amount must be greater than 0")
+	}
+	return amount, nil
+}
+
+func (server *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found: %s", accountID, err)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account [%d]: %s", accountID, err)
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
+	}
+
+	return account, nil
+}
+
+func (server *Server) validQuote(ctx context.Context, quoteID uuid.UUID, username string) (db.FxQuote, error) {
+	quote, err := server.store.GetFxQuote(ctx, quoteID)
+	if err != nil {
+		return quote, storeError(err, "failed to get exchange quote")
+	}
+
+	if quote.Username != username {
+		return quote, status.Errorf(codes.PermissionDenied, "exchange quote does not belong to the authenticated user")
+	}
+
+	return quote, nil
+}
@@ -0,0 +1,576 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateTransferAPI(t *testing.T) {
+	amount := int64(10)
+
+	user1 := util.RandomOwner()
+	user2 := util.RandomOwner()
+
+	account1 := randomAccount(user1, util.IDR)
+	account2 := randomAccount(user2, util.IDR)
+	account2.ID = account1.ID + 1
+	account3 := randomAccount(user1, util.USD)
+	account3.ID = account1.ID + 2
+
+	quote := db.FxQuote{
+		ID:           uuid.New(),
+		Username:     user1,
+		FromCurrency: util.USD,
+		ToCurrency:   util.IDR,
+		Amount:       amount,
+		ToAmount:     amount * 15500,
+		ExchangeRate: "15500",
+		Spread:       "0",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateTransferRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        util.NewMoney(amount, util.IDR),
+				}
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount, ToAmount: amount},
+					FromAccount: account1,
+					ToAccount:   account2,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account1.ID, rsp.GetTransfer().GetFromAccountId())
+				require.Equal(t, "0.10", rsp.GetDebited().GetAmount())
+				require.Equal(t, util.IDR, rsp.GetCredited().GetCurrency())
+			},
+		},
+		{
+			name: "DecimalMoney",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account3.ID,
+				ToAccountId:   account1.ID,
+				Money:         &pb.Money{Amount: "0.10", Currency: util.USD},
+				ToCurrency:    util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, util.NewMoney(amount, util.USD), arg.Amount)
+						require.NotNil(t, arg.Exchange)
+						require.Equal(t, util.IDR, arg.Exchange.ToAmount.Currency)
+						require.Positive(t, arg.Exchange.ToAmount.Amount)
+						return db.TransferTxResult{FromAccount: account3, ToAccount: account1}, nil
+					})
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, rsp)
+			},
+		},
+		{
+			name: "AmountAndMoney",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Money:         &pb.Money{Amount: "10", Currency: util.IDR},
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "FromAccountNotOwned",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account2.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account3.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "BalanceOverflow",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, util.ErrAmountOverflow)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "Quote",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account3.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				QuoteId:       quote.ID.String(),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account3.ID,
+					ToAccountID:   account1.ID,
+					Amount:        util.NewMoney(amount, util.USD),
+					QuoteID:       &quote.ID,
+				}
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{FromAccountID: account3.ID, ToAccountID: account1.ID, Amount: amount, ToAmount: quote.ToAmount},
+					FromAccount: account3,
+					ToAccount:   account1,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.IDR, rsp.GetCredited().GetCurrency())
+				require.Equal(t, quote.ToAmount, rsp.GetTransfer().GetToAmount())
+			},
+		},
+		{
+			name: "QuoteExpired",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account3.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				QuoteId:       quote.ID.String(),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrQuoteExpired)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "QuoteNotOwned",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account3.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				QuoteId:       quote.ID.String(),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user2)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "QuoteCurrencyMismatch",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account3.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+				QuoteId:       quote.ID.String(),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "QuoteNotFound",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account3.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				QuoteId:       quote.ID.String(),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(db.FxQuote{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidQuoteID",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account3.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				QuoteId:       "invalid",
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.CreateTransfer(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
+
+func TestCreateTransferIdempotency(t *testing.T) {
+	amount := int64(10)
+
+	user1 := util.RandomOwner()
+	user2 := util.RandomOwner()
+
+	account1 := randomAccount(user1, util.IDR)
+	account2 := randomAccount(user2, util.IDR)
+	account2.ID = account1.ID + 1
+
+	result := db.TransferTxResult{
+		Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount, ToAmount: amount},
+		FromAccount: account1,
+		ToAccount:   account2,
+	}
+
+	testCases := []struct {
+		name           string
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore, idempotencyKey string)
+		check          func(t *testing.T, rsp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:           "OK",
+			idempotencyKey: util.RandomString(16),
+			buildStubs: func(store *mockdb.MockStore, idempotencyKey string) {
+				store.EXPECT().
+					ReplayTransferTx(gomock.Any(), gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams, _ string) (db.TransferTxResult, bool, error) {
+						require.NotNil(t, arg.Idempotency)
+						require.Equal(t, idempotencyKey, arg.Idempotency.Key)
+						require.Equal(t, user1, arg.Idempotency.Username)
+						return db.TransferTxResult{}, false, nil
+					})
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.NotNil(t, arg.Idempotency)
+						require.Equal(t, idempotencyKey, arg.Idempotency.Key)
+						require.True(t, arg.Idempotency.ExpiresAt.After(time.Now()))
+						return result, nil
+					})
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, result.Transfer.ID, rsp.GetTransfer().GetId())
+			},
+		},
+		{
+			name:           "Replay",
+			idempotencyKey: util.RandomString(16),
+			buildStubs: func(store *mockdb.MockStore, idempotencyKey string) {
+				store.EXPECT().ReplayTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(result, true, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, result.Transfer.ID, rsp.GetTransfer().GetId())
+				require.Equal(t, "0.10", rsp.GetDebited().GetAmount())
+			},
+		},
+		{
+			name:           "KeyMismatch",
+			idempotencyKey: util.RandomString(16),
+			buildStubs: func(store *mockdb.MockStore, idempotencyKey string) {
+				store.EXPECT().
+					ReplayTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, false, db.ErrIdempotencyKeyMismatch)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoKey",
+			buildStubs: func(store *mockdb.MockStore, idempotencyKey string) {
+				store.EXPECT().ReplayTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Nil(t, arg.Idempotency)
+						return result, nil
+					})
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:           "KeyTooLong",
+			idempotencyKey: util.RandomString(maxIdempotencyKeyLength + 1),
+			buildStubs: func(store *mockdb.MockStore, idempotencyKey string) {
+				store.EXPECT().ReplayTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:           "ReplayError",
+			idempotencyKey: util.RandomString(16),
+			buildStubs: func(store *mockdb.MockStore, idempotencyKey string) {
+				store.EXPECT().
+					ReplayTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, false, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.idempotencyKey)
+
+			ctx := newContextWithAuthPayload(t, user1)
+			if tc.idempotencyKey != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(idempotencyKeyHeader, tc.idempotencyKey))
+			}
+
+			server := newTestServer(t, store)
+			rsp, err := server.CreateTransfer(ctx, &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			})
+			tc.check(t, rsp, err)
+		})
+	}
+}
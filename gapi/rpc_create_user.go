@@ -2,8 +2,8 @@ package gapi
 
 import (
 	"context"
+	"errors"
 
-	"github.com/lib/pq"
 	db "github.com/superjantung/bankita-api/db/sqlc"
 	"github.com/superjantung/bankita-api/pb"
 	"github.com/superjantung/bankita-api/util"
@@ -26,11 +26,8 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 
 	user, err := server.store.CreateUser(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				return nil, status.Errorf(codes.AlreadyExists, "username already exists: %s", err)
-			}
+		if errors.Is(err, db.ErrUniqueViolation) {
+			return nil, status.Errorf(codes.AlreadyExists, "username already exists: %s", err)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
 	}
@@ -0,0 +1,69 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.validCashAccount(ctx, req.GetAccountId(), req.GetAmount(), req.GetCurrency(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.DepositTxParams{
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+	}
+
+	result, err := server.store.DepositTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "failed to deposit")
+	}
+
+	rsp := &pb.DepositResponse{
+		Account: convertAccount(result.Account),
+		Entry:   convertEntry(result.Entry),
+	}
+	return rsp, nil
+}
+
+// validCashAccount checks a deposit or withdrawal. Cash is handed over at the
+// counter, so only operators can book it, on any customer account.
+func (server *Server) validCashAccount(ctx context.Context, accountID int64, amount int64, currency string, username string) error {
+	if accountID < 1 {
+		return status.Errorf(codes.InvalidArgument, "invalid account id")
+	}
+	if amount <= 0 {
+		return status.Errorf(codes.InvalidArgument, "amount must be greater than 0")
+	}
+
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		return storeError(err, "failed to get user")
+	}
+
+	if user.Role != util.OperatorRole {
+		return status.Errorf(codes.PermissionDenied, "only operators can deposit and withdraw cash")
+	}
+
+	account, err := server.validAccount(ctx, accountID, currency)
+	if err != nil {
+		return err
+	}
+
+	if account.Owner == db.SettlementAccountOwner {
+		return status.Errorf(codes.InvalidArgument, "cash cannot be deposited to or withdrawn from a settlement account")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id: %d", req.GetId())
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "account not found: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+
+	rsp := &pb.GetAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
@@ -0,0 +1,113 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetAccountAPI(t *testing.T) {
+	user := util.RandomOwner()
+	account := randomAccount(user, util.USD)
+
+	testCases := []struct {
+		name       string
+		req        *pb.GetAccountRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.GetAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.GetAccountRequest{Id: account.ID},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			check: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, rsp.GetAccount().GetId())
+				require.Equal(t, account.Balance, rsp.GetAccount().GetBalance())
+			},
+		},
+		{
+			name: "NotFound",
+			req:  &pb.GetAccountRequest{Id: account.ID},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+			},
+			check: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NotOwner",
+			req:  &pb.GetAccountRequest{Id: account.ID},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, util.RandomOwner())
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			check: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidID",
+			req:  &pb.GetAccountRequest{Id: 0},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.GetAccountRequest{Id: account.ID},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.GetAccount(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
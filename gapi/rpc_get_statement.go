@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxStatementPeriod = 366 * 24 * time.Hour
+
+func (server *Server) GetStatement(ctx context.Context, req *pb.GetStatementRequest) (*pb.GetStatementResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetAccountId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id: %d", req.GetAccountId())
+	}
+	if req.GetFrom() == nil || req.GetTo() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "from and to are required")
+	}
+	if err := req.GetFrom().CheckValid(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid from: %s", err)
+	}
+	if err := req.GetTo().CheckValid(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid to: %s", err)
+	}
+
+	from := req.GetFrom().AsTime()
+	to := req.GetTo().AsTime()
+	if !to.After(from) {
+		return nil, status.Errorf(codes.InvalidArgument, "to must be after from")
+	}
+	if to.Sub(from) > maxStatementPeriod {
+		return nil, status.Errorf(codes.InvalidArgument, "statement period must be at most %s", maxStatementPeriod)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, storeError(err, "failed to get account")
+	}
+
+	if account.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+
+	statement, err := server.store.StatementTx(ctx, db.StatementTxParams{
+		AccountID: req.GetAccountId(),
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		return nil, storeError(err, "failed to get statement")
+	}
+
+	rsp := &pb.GetStatementResponse{
+		Account:        convertAccount(statement.Account),
+		From:           timestamppb.New(statement.From),
+		To:             timestamppb.New(statement.To),
+		OpeningBalance: statement.OpeningBalance,
+		ClosingBalance: statement.ClosingBalance,
+		Entries:        convertStatementEntries(statement.Entries),
+	}
+	return rsp, nil
+}
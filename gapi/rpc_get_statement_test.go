@@ -0,0 +1,243 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user := util.RandomOwner()
+	account := randomAccount(user, util.USD)
+	counterparty := randomAccount(util.RandomOwner(), util.USD)
+
+	to := time.Now().UTC().Truncate(time.Second)
+	from := to.Add(-30 * 24 * time.Hour)
+
+	statement := db.StatementTxResult{
+		Account:        account,
+		From:           from,
+		To:             to,
+		OpeningBalance: account.Balance + 10,
+		ClosingBalance: account.Balance,
+		Entries: []db.StatementEntry{
+			{
+				Entry:                 db.Entry{ID: 1, AccountID: account.ID, Amount: -10, CreatedAt: from.Add(time.Hour)},
+				Balance:               account.Balance,
+				CounterpartyAccountID: counterparty.ID,
+				CounterpartyOwner:     counterparty.Owner,
+			},
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		req        *pb.GetStatementRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.GetStatementResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.StatementTxParams{
+					AccountID: account.ID,
+					From:      from,
+					To:        to,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(statement, nil)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, rsp.GetAccount().GetId())
+				require.Equal(t, statement.OpeningBalance, rsp.GetOpeningBalance())
+				require.Equal(t, statement.ClosingBalance, rsp.GetClosingBalance())
+				require.True(t, from.Equal(rsp.GetFrom().AsTime()))
+				require.True(t, to.Equal(rsp.GetTo().AsTime()))
+				require.Len(t, rsp.GetEntries(), 1)
+				require.Equal(t, int64(-10), rsp.GetEntries()[0].GetEntry().GetAmount())
+				require.Equal(t, counterparty.ID, rsp.GetEntries()[0].GetCounterpartyAccountId())
+				require.Equal(t, counterparty.Owner, rsp.GetEntries()[0].GetCounterpartyOwner())
+			},
+		},
+		{
+			name: "InvalidAccountID",
+			req: &pb.GetStatementRequest{
+				AccountId: 0,
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "MissingPeriod",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(from),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "ToBeforeFrom",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(to),
+				To:        timestamppb.New(from),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "PeriodTooLong",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(to.Add(-maxStatementPeriod - time.Hour)),
+				To:        timestamppb.New(to),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NotOwner",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, util.RandomOwner())
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(1).Return(db.StatementTxResult{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+			},
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.GetStatementResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.GetStatement(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetPage() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "page must be at least 1")
+	}
+	if req.GetLimit() < 1 || req.GetLimit() > 20 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and 20")
+	}
+
+	arg := db.ListAccountsParams{
+		Owner:  authPayload.Username,
+		Limit:  req.GetLimit(),
+		Offset: (req.GetPage() - 1) * req.GetLimit(),
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts: convertAccounts(accounts),
+	}
+	return rsp, nil
+}
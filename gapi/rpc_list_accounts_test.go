@@ -0,0 +1,124 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListAccountsAPI(t *testing.T) {
+	user := util.RandomOwner()
+	accounts := []db.Account{
+		randomAccount(user, util.USD),
+		randomAccount(user, util.IDR),
+	}
+
+	testCases := []struct {
+		name       string
+		req        *pb.ListAccountsRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.ListAccountsResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ListAccountsRequest{Page: 2, Limit: 5},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsParams{
+					Owner:  user,
+					Limit:  5,
+					Offset: 5,
+				}
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			check: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetAccounts(), len(accounts))
+				for i, account := range rsp.GetAccounts() {
+					require.Equal(t, accounts[i].ID, account.GetId())
+					require.Equal(t, user, account.GetOwner())
+				}
+			},
+		},
+		{
+			name: "InvalidPage",
+			req:  &pb.ListAccountsRequest{Page: 0, Limit: 5},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidLimit",
+			req:  &pb.ListAccountsRequest{Page: 1, Limit: 21},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.ListAccountsRequest{Page: 1, Limit: 5},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.ListAccountsRequest{Page: 1, Limit: 5},
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.ListAccounts(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
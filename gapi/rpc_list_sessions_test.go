@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListSessionsAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore, session db.Session)
+		check      func(t *testing.T, rsp *pb.ListSessionsResponse, session db.Session, err error)
+	}{
+		{
+			name: "OK",
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, username)
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Eq(username)).Times(1).Return([]db.Session{session}, nil)
+			},
+			check: func(t *testing.T, rsp *pb.ListSessionsResponse, session db.Session, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetSessions(), 1)
+				require.Equal(t, session.ID.String(), rsp.GetSessions()[0].GetId())
+			},
+		},
+		{
+			name: "InternalError",
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, username)
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.ListSessionsResponse, session db.Session, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.ListSessionsResponse, session db.Session, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			session, _ := randomSession(t, server.tokenMaker, username)
+			tc.buildStubs(store, session)
+
+			rsp, err := server.ListSessions(tc.buildCtx(t), &pb.ListSessionsRequest{})
+			tc.check(t, rsp, session, err)
+		})
+	}
+}
@@ -2,7 +2,7 @@ package gapi
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 
 	db "github.com/superjantung/bankita-api/db/sqlc"
 	"github.com/superjantung/bankita-api/pb"
@@ -15,7 +15,7 @@ import (
 func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
 	user, err := server.store.GetUser(ctx, req.GetUsername())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, db.ErrRecordNotFound) {
 			return nil, status.Errorf(codes.NotFound, "rows not found: %s", err)
 		}
 		return nil, status.Errorf(codes.Internal, "internal server error: %s", err)
@@ -26,28 +26,31 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		return nil, status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateRefreshToken(
 		user.Username,
-		server.config.AccessTokenDuration,
+		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "internal server error: %s", err)
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+	accessToken, accessPayload, err := server.tokenMaker.CreateAccessToken(
 		user.Username,
+		refreshPayload.ID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "internal server error: %s", err)
 	}
 
+	mtdt := extractMetadata(ctx, server.trustedProxies)
 	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
 		ID:           refreshPayload.ID,
+		FamilyID:     refreshPayload.ID,
 		Username:     user.Username,
 		RefreshToken: refreshToken,
-		UserAgent:    "",
-		ClientIp:     "",
+		UserAgent:    mtdt.UserAgent,
+		ClientIp:     mtdt.ClientIP,
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	})
@@ -0,0 +1,38 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) LogoutUser(ctx context.Context, req *pb.LogoutUserRequest) (*pb.LogoutUserResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken(), token.TokenTypeRefreshToken)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+
+	session, err := server.validSession(ctx, refreshPayload.ID, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "mismatched session token")
+	}
+
+	err = server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	return &pb.LogoutUserResponse{}, nil
+}
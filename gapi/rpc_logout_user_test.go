@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogoutUserAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		username   string
+		buildReq   func(session db.Session, refreshToken string) *pb.LogoutUserRequest
+		buildStubs func(store *mockdb.MockStore, session db.Session)
+		code       codes.Code
+	}{
+		{
+			name:     "OK",
+			username: username,
+			buildReq: func(session db.Session, refreshToken string) *pb.LogoutUserRequest {
+				return &pb.LogoutUserRequest{RefreshToken: refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).Times(1).Return(nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "OtherUsersSession",
+			username: util.RandomOwner(),
+			buildReq: func(session db.Session, refreshToken string) *pb.LogoutUserRequest {
+				return &pb.LogoutUserRequest{RefreshToken: refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:     "SessionNotFound",
+			username: username,
+			buildReq: func(session db.Session, refreshToken string) *pb.LogoutUserRequest {
+				return &pb.LogoutUserRequest{RefreshToken: refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:     "MismatchedToken",
+			username: username,
+			buildReq: func(session db.Session, refreshToken string) *pb.LogoutUserRequest {
+				return &pb.LogoutUserRequest{RefreshToken: refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = "rotated"
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "InvalidRefreshToken",
+			username: username,
+			buildReq: func(session db.Session, refreshToken string) *pb.LogoutUserRequest {
+				return &pb.LogoutUserRequest{RefreshToken: "invalid"}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "BlockSessionFamilyError",
+			username: username,
+			buildReq: func(session db.Session, refreshToken string) *pb.LogoutUserRequest {
+				return &pb.LogoutUserRequest{RefreshToken: refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).Times(1).Return(sql.ErrConnDone)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			session, refreshToken := randomSession(t, server.tokenMaker, username)
+			tc.buildStubs(store, session)
+
+			ctx := newContextWithAuthPayload(t, tc.username)
+			_, err := server.LogoutUser(ctx, tc.buildReq(session, refreshToken))
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
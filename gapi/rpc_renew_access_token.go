@@ -0,0 +1,106 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken(), token.TokenTypeRefreshToken)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "session not found: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if session.IsBlocked {
+		return nil, status.Errorf(codes.Unauthenticated, "blocked session")
+	}
+
+	if session.Username != refreshPayload.Username {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect session user")
+	}
+
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "mismatched session token")
+	}
+
+	if session.IsRotated {
+		return nil, server.rejectReusedRefreshToken(ctx, session.FamilyID)
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "expired session")
+	}
+
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateRefreshToken(
+		refreshPayload.Username,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %s", err)
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateAccessToken(
+		refreshPayload.Username,
+		newRefreshPayload.ID,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	mtdt := extractMetadata(ctx, server.trustedProxies)
+	result, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newRefreshPayload.ID,
+			Username:     refreshPayload.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    mtdt.UserAgent,
+			ClientIp:     mtdt.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrSessionRotated) {
+			return nil, server.rejectReusedRefreshToken(ctx, session.FamilyID)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to rotate session: %s", err)
+	}
+
+	rsp := &pb.RenewAccessTokenResponse{
+		SessionId:             result.NewSession.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(newRefreshPayload.ExpiredAt),
+	}
+	return rsp, nil
+}
+
+// rejectReusedRefreshToken blocks every session descended from the same login
+// and returns the error to send back to the caller.
+func (server *Server) rejectReusedRefreshToken(ctx context.Context, familyID uuid.UUID) error {
+	err := server.store.BlockSessionFamily(ctx, familyID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to block session family: %s", err)
+	}
+	return status.Errorf(codes.Unauthenticated, "refresh token reuse detected")
+}
@@ -0,0 +1,186 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/token"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	username := util.RandomOwner()
+	familyID := uuid.New()
+
+	testCases := []struct {
+		name         string
+		buildSession func(session *db.Session)
+		buildStubs   func(store *mockdb.MockStore, session db.Session)
+		check        func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error)
+	}{
+		{
+			name:         "OK",
+			buildSession: func(session *db.Session) {},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+						require.Equal(t, session.ID, arg.SessionID)
+						require.Equal(t, username, arg.NewSession.Username)
+						return db.RotateSessionTxResult{NewSession: db.Session{ID: arg.NewSession.ID}}, nil
+					})
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.GetAccessToken())
+				require.NotEmpty(t, rsp.GetRefreshToken())
+				require.NotEmpty(t, rsp.GetSessionId())
+			},
+		},
+		{
+			name: "ReusedRefreshToken",
+			buildSession: func(session *db.Session) {
+				session.IsRotated = true
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Eq(familyID)).Times(1).Return(nil)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:         "ConcurrentRotation",
+			buildSession: func(session *db.Session) {},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RotateSessionTxResult{}, db.ErrSessionRotated)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Eq(familyID)).Times(1).Return(nil)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildSession: func(session *db.Session) {
+				session.IsBlocked = true
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "MismatchedToken",
+			buildSession: func(session *db.Session) {
+				session.RefreshToken = "other"
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ExpiredSession",
+			buildSession: func(session *db.Session) {
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:         "SessionNotFound",
+			buildSession: func(session *db.Session) {},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:         "InternalError",
+			buildSession: func(session *db.Session) {},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, sql.ErrConnDone)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			refreshToken, payload, err := server.tokenMaker.CreateRefreshToken(username, time.Hour)
+			require.NoError(t, err)
+
+			session := db.Session{
+				ID:           payload.ID,
+				Username:     username,
+				RefreshToken: refreshToken,
+				ExpiresAt:    payload.ExpiredAt,
+				FamilyID:     familyID,
+			}
+			tc.buildSession(&session)
+			tc.buildStubs(store, session)
+
+			req := &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}
+			rsp, err := server.RenewAccessToken(context.Background(), req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
+
+func TestRenewAccessTokenInvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	otherMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	refreshToken, _, err := otherMaker.CreateRefreshToken(util.RandomOwner(), time.Hour)
+	require.NoError(t, err)
+
+	_, err = server.RenewAccessToken(context.Background(), &pb.RenewAccessTokenRequest{RefreshToken: refreshToken})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
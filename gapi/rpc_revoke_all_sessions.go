@@ -0,0 +1,23 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/superjantung/bankita-api/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.store.BlockUserSessions(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block sessions: %s", err)
+	}
+
+	return &pb.RevokeAllSessionsResponse{}, nil
+}
@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevokeAllSessionsAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Eq(username)).Times(1).Return(nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "InternalError",
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Eq(username)).Times(1).Return(sql.ErrConnDone)
+			},
+			code: codes.Internal,
+		},
+		{
+			name: "NoAuthorization",
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			_, err := server.RevokeAllSessions(tc.buildCtx(t), &pb.RevokeAllSessionsRequest{})
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session id: %s", err)
+	}
+
+	session, err := server.validSession(ctx, sessionID, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+func (server *Server) validSession(ctx context.Context, sessionID uuid.UUID, username string) (db.Session, error) {
+	session, err := server.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return session, status.Errorf(codes.NotFound, "session not found: %s", err)
+		}
+		return session, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if session.Username != username {
+		return session, status.Errorf(codes.PermissionDenied, "session does not belong to the authenticated user")
+	}
+
+	return session, nil
+}
@@ -0,0 +1,109 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevokeSessionAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		username   string
+		sessionID  func(session db.Session) string
+		buildStubs func(store *mockdb.MockStore, session db.Session)
+		code       codes.Code
+	}{
+		{
+			name:      "OK",
+			username:  username,
+			sessionID: func(session db.Session) string { return session.ID.String() },
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).Times(1).Return(nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:      "OtherUsersSession",
+			username:  util.RandomOwner(),
+			sessionID: func(session db.Session) string { return session.ID.String() },
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:      "SessionNotFound",
+			username:  username,
+			sessionID: func(session db.Session) string { return session.ID.String() },
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:      "InvalidID",
+			username:  username,
+			sessionID: func(session db.Session) string { return "invalid" },
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:      "BlockSessionFamilyError",
+			username:  username,
+			sessionID: func(session db.Session) string { return session.ID.String() },
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).Times(1).Return(sql.ErrConnDone)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			session, _ := randomSession(t, server.tokenMaker, username)
+			tc.buildStubs(store, session)
+
+			ctx := newContextWithAuthPayload(t, tc.username)
+			_, err := server.RevokeSession(ctx, &pb.RevokeSessionRequest{SessionId: tc.sessionID(session)})
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestRevokeSessionNoAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	_, err := server.RevokeSession(context.Background(), &pb.RevokeSessionRequest{SessionId: util.RandomString(8)})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpdateOverdraftLimit sets how far below zero an account may go. Granting
+// credit is a bank decision, so only operators can change it.
+func (server *Server) UpdateOverdraftLimit(ctx context.Context, req *pb.UpdateOverdraftLimitRequest) (*pb.UpdateOverdraftLimitResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetAccountId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id")
+	}
+	if req.GetOverdraftLimit() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "overdraft limit must not be negative")
+	}
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		return nil, storeError(err, "failed to get user")
+	}
+
+	if user.Role != util.OperatorRole {
+		return nil, status.Errorf(codes.PermissionDenied, "only operators can change overdraft limits")
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, storeError(err, "failed to get account")
+	}
+
+	if account.Owner == db.SettlementAccountOwner {
+		return nil, status.Errorf(codes.InvalidArgument, "settlement accounts have no overdraft limit")
+	}
+
+	account, err = server.store.UpdateAccountOverdraftLimit(ctx, db.UpdateAccountOverdraftLimitParams{
+		ID:             req.GetAccountId(),
+		OverdraftLimit: req.GetOverdraftLimit(),
+	})
+	if err != nil {
+		return nil, storeError(err, "failed to update overdraft limit")
+	}
+
+	rsp := &pb.UpdateOverdraftLimitResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
@@ -0,0 +1,158 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/superjantung/bankita-api/db/mock"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+	"github.com/superjantung/bankita-api/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateOverdraftLimitAPI(t *testing.T) {
+	limit := int64(5000)
+	operator := db.User{Username: util.RandomOwner(), Role: util.OperatorRole}
+	user := db.User{Username: util.RandomOwner(), Role: util.DepositorRole}
+	account := randomAccount(user.Username, util.USD)
+	settlement := randomAccount(db.SettlementAccountOwner, util.USD)
+
+	testCases := []struct {
+		name       string
+		req        *pb.UpdateOverdraftLimitRequest
+		buildCtx   func(t *testing.T) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.UpdateOverdraftLimitRequest{AccountId: account.ID, OverdraftLimit: limit},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.UpdateAccountOverdraftLimitParams{ID: account.ID, OverdraftLimit: limit}
+				updated := account
+				updated.OverdraftLimit = limit
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			check: func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, rsp.GetAccount().GetId())
+				require.Equal(t, limit, rsp.GetAccount().GetOverdraftLimit())
+			},
+		},
+		{
+			name: "NegativeLimit",
+			req:  &pb.UpdateOverdraftLimitRequest{AccountId: account.ID, OverdraftLimit: -limit},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NotOperator",
+			req:  &pb.UpdateOverdraftLimitRequest{AccountId: account.ID, OverdraftLimit: limit},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, user.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req:  &pb.UpdateOverdraftLimitRequest{AccountId: account.ID, OverdraftLimit: limit},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "SettlementAccount",
+			req:  &pb.UpdateOverdraftLimitRequest{AccountId: settlement.ID, OverdraftLimit: limit},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(settlement.ID)).Times(1).Return(settlement, nil)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.UpdateOverdraftLimitRequest{AccountId: account.ID, OverdraftLimit: limit},
+			buildCtx: func(t *testing.T) context.Context {
+				return newContextWithAuthPayload(t, operator.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(operator.Username)).Times(1).Return(operator, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.UpdateOverdraftLimitRequest{AccountId: account.ID, OverdraftLimit: limit},
+			buildCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateAccountOverdraftLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, rsp *pb.UpdateOverdraftLimitResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rsp, err := server.UpdateOverdraftLimit(tc.buildCtx(t), tc.req)
+			tc.check(t, rsp, err)
+		})
+	}
+}
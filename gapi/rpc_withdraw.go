@@ -0,0 +1,36 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/pb"
+)
+
+func (server *Server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	authPayload, err := authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.validCashAccount(ctx, req.GetAccountId(), req.GetAmount(), req.GetCurrency(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.WithdrawTxParams{
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+	}
+
+	result, err := server.store.WithdrawTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err, "failed to withdraw")
+	}
+
+	rsp := &pb.WithdrawResponse{
+		Account: convertAccount(result.Account),
+		Entry:   convertEntry(result.Entry),
+	}
+	return rsp, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	db "github.com/superjantung/bankita-api/db/sqlc"
+	"github.com/superjantung/bankita-api/fx"
 	"github.com/superjantung/bankita-api/pb"
 	"github.com/superjantung/bankita-api/token"
 	"github.com/superjantung/bankita-api/util"
@@ -11,9 +12,11 @@ import (
 
 type Server struct {
 	pb.UnimplementedBankitaServer
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
+	config         util.Config
+	store          db.Store
+	tokenMaker     token.Maker
+	rateProvider   fx.RateProvider
+	trustedProxies util.TrustedProxies
 }
 
 func NewServer(config util.Config, store db.Store) (*Server, error) {
@@ -22,10 +25,22 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+
+	trustedProxies, err := util.ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse trusted proxies: %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:         config,
+		store:          store,
+		tokenMaker:     tokenMaker,
+		rateProvider:   rateProvider,
+		trustedProxies: trustedProxies,
 	}
 
 	return server, nil
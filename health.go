@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/superjantung/bankita-api/pb"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	healthCheckInterval = 10 * time.Second
+	healthCheckTimeout  = 2 * time.Second
+)
+
+// healthChecker reports liveness and readiness over HTTP and keeps the
+// grpc.health.v1 statuses in sync with the same readiness checks.
+type healthChecker struct {
+	connPool     *pgxpool.Pool
+	grpcHealth   *health.Server
+	migrated     atomic.Bool
+	shuttingDown atomic.Bool
+}
+
+func newHealthChecker(connPool *pgxpool.Pool) *healthChecker {
+	checker := &healthChecker{
+		connPool:   connPool,
+		grpcHealth: health.NewServer(),
+	}
+	checker.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	return checker
+}
+
+func (checker *healthChecker) setMigrated(migrated bool) {
+	checker.migrated.Store(migrated)
+}
+
+func (checker *healthChecker) ready(ctx context.Context) error {
+	if checker.shuttingDown.Load() {
+		return errors.New("server is shutting down")
+	}
+	if !checker.migrated.Load() {
+		return errors.New("database schema is not up to date")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	return checker.connPool.Ping(ctx)
+}
+
+func (checker *healthChecker) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	checker.grpcHealth.SetServingStatus("", status)
+	checker.grpcHealth.SetServingStatus(pb.Bankita_ServiceDesc.ServiceName, status)
+}
+
+// run refreshes the gRPC health status until ctx is done, then reports
+// NOT_SERVING for the rest of the graceful shutdown.
+func (checker *healthChecker) run(ctx context.Context, waitGroup *errgroup.Group) {
+	waitGroup.Go(func() error {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			status := healthpb.HealthCheckResponse_SERVING
+			if err := checker.ready(ctx); err != nil {
+				log.Warn().Err(err).Msg("server is not ready")
+				status = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+			checker.setServingStatus(status)
+
+			select {
+			case <-ctx.Done():
+				checker.shuttingDown.Store(true)
+				checker.grpcHealth.Shutdown()
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// waitForDrain keeps the servers running for delay after readiness turned
+// NOT_SERVING, so that load balancers stop routing new requests to them before
+// they stop accepting connections.
+func waitForDrain(delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	log.Info().Dur("delay", delay).Msg("waiting for load balancers to drain")
+	time.Sleep(delay)
+}
+
+func (checker *healthChecker) healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, nil)
+}
+
+func (checker *healthChecker) readyz(w http.ResponseWriter, r *http.Request) {
+	if err := checker.ready(r.Context()); err != nil {
+		writeHealth(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeHealth(w, http.StatusOK, nil)
+}
+
+func writeHealth(w http.ResponseWriter, statusCode int, err error) {
+	rsp := map[string]string{"status": "ok"}
+	if err != nil {
+		rsp = map[string]string{"status": "unavailable", "error": err.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(rsp)
+}
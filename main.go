@@ -2,70 +2,321 @@ package main
 
 import (
 	"context"
-	"database/sql"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
 	"github.com/superjantung/bankita-api/api"
 	db "github.com/superjantung/bankita-api/db/sqlc"
 	"github.com/superjantung/bankita-api/gapi"
 	"github.com/superjantung/bankita-api/pb"
 	"github.com/superjantung/bankita-api/util"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
-
-	_ "github.com/lib/pq"
 )
 
+var interruptSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGTERM,
+}
+
 func main() {
+	modeFlag := flag.String("mode", "", "comma separated servers to run: gin, grpc, gateway or all (overrides SERVER_MODES)")
+	flag.Parse()
+
 	config, err := util.LoadConfig(".")
 	if err != nil {
-		log.Fatal("cannot load config:", err)
+		log.Fatal().Err(err).Msg("cannot load config")
+	}
+
+	err = util.SetupLogger(config.Environment, config.LogLevel)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot set up logger")
+	}
+
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "migrate" {
+		err = runMigrateCommand(config, args[1:])
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot run migration")
+		}
+		return
+	}
+
+	if *modeFlag != "" {
+		config.ServerModes = *modeFlag
+	}
+
+	modes, err := util.ParseServerModes(config.ServerModes)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid server modes")
 	}
 
-	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	err = config.ValidateServerAddresses(modes)
 	if err != nil {
-		log.Fatal("cannot connect to database: ", err)
+		log.Fatal().Err(err).Msg("invalid server addresses")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), interruptSignals...)
+	defer stop()
+
+	shutdownTracing, err := setupTracing(ctx, config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot set up tracing")
+	}
+
+	connPool, err := connectDB(ctx, config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot connect to database")
+	}
+
+	checker := newHealthChecker(connPool)
+
+	if config.AutoMigrate {
+		err = runDBMigration(config)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot run db migration")
+		}
+		checker.setMigrated(true)
+	} else {
+		upToDate, err := schemaUpToDate(config)
+		if err != nil {
+			log.Error().Err(err).Msg("cannot check db migration version")
+		}
+		checker.setMigrated(upToDate)
+	}
+
+	store := db.NewStore(connPool)
+	prometheus.MustRegister(db.NewPoolCollector(connPool))
+
+	// Without the currencies table the built-in currencies stay in use until
+	// the schema is migrated and the server restarted.
+	if checker.migrated.Load() {
+		err = loadCurrencies(ctx, store)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot load currencies")
+		}
+
+		err = reportUnmatchedTransferLegs(ctx, store)
+		if err != nil {
+			log.Error().Err(err).Msg("cannot check unmatched transfer legs")
+		}
 	}
 
-	store := db.NewStore(conn)
+	waitGroup, ctx := errgroup.WithContext(ctx)
 
-	go runGatewayServer(config, store)
-	runGrpcServer(config, store)
+	checker.run(ctx, waitGroup)
+	runIdempotencyKeyPurger(ctx, waitGroup, store, config.IdempotencyKeyPurgeInterval)
+	if modes[util.GRPCServer] {
+		runGrpcServer(ctx, waitGroup, config, store, checker)
+	}
+	if modes[util.GatewayServer] {
+		runGatewayServer(ctx, waitGroup, config, checker)
+	}
+	if modes[util.GinServer] {
+		runGinServer(ctx, waitGroup, config, store)
+	}
+	runMetricsServer(ctx, waitGroup, config)
+
+	err = waitGroup.Wait()
+	connPool.Close()
+
+	// Flush the spans of the requests drained during shutdown.
+	flushCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Error().Err(err).Msg("cannot flush traces")
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("error from wait group")
+	}
+	log.Info().Msg("all servers are stopped")
 }
 
-func runGrpcServer(config util.Config, store db.Store) {
-	server, err := gapi.NewServer(config, store)
+// connectDB creates the connection pool and waits until the database answers
+// a ping, retrying with backoff for up to DBConnectTimeout so the server can
+// start alongside a database that is still booting.
+func connectDB(ctx context.Context, config util.Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(config.DBSource)
 	if err != nil {
-		log.Fatal("cannot create server: ", err)
+		return nil, err
 	}
 
-	grpcServer := grpc.NewServer()
-	pb.RegisterBankitaServer(grpcServer, server)
-	reflection.Register(grpcServer)
+	if config.DBMaxConns > 0 {
+		poolConfig.MaxConns = config.DBMaxConns
+	}
+	if config.DBMinConns > 0 {
+		poolConfig.MinConns = config.DBMinConns
+	}
+	if config.DBMaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = config.DBMaxConnLifetime
+	}
+	if config.DBMaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = config.DBMaxConnIdleTime
+	}
+	if config.DBHealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = config.DBHealthCheckPeriod
+	}
+	poolConfig.ConnConfig.Tracer = db.QueryTracer{}
 
-	listener, err := net.Listen("tcp", config.GRPCServerAddress)
+	connPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatal("cannot create listener: ", err)
+		return nil, err
 	}
 
-	log.Printf("start GRPC server: %s", listener.Addr().String())
-	err = grpcServer.Serve(listener)
+	ctx, cancel := context.WithTimeout(ctx, config.DBConnectTimeout)
+	defer cancel()
+
+	backoff := 500 * time.Millisecond
+	for {
+		err = connPool.Ping(ctx)
+		if err == nil {
+			return connPool, nil
+		}
 
+		log.Warn().Err(err).Dur("retry_in", backoff).Msg("database is not ready")
+		select {
+		case <-ctx.Done():
+			connPool.Close()
+			return nil, err
+		case <-time.After(backoff):
+		}
+
+		if backoff < 5*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// loadCurrencies replaces the built-in currency registry with the
+// currencies table.
+func loadCurrencies(ctx context.Context, store db.Store) error {
+	rows, err := store.ListCurrencies(ctx)
 	if err != nil {
-		log.Fatal("cannot start grpc server: ", err)
+		return err
+	}
+
+	currencies := make([]util.Currency, len(rows))
+	for i, row := range rows {
+		currencies[i] = util.Currency{
+			Code:      row.Code,
+			MinorUnit: int(row.MinorUnit),
+			Symbol:    row.Symbol,
+			Enabled:   row.Enabled,
+		}
 	}
+	util.Currencies.Load(currencies)
+
+	log.Info().Int("count", len(currencies)).Msg("loaded currencies")
+	return nil
+}
+
+// reportUnmatchedTransferLegs warns about transfers whose entries the
+// statement backfill could not link to them. Until they are fixed, those legs
+// show up on statements without a counterparty.
+func reportUnmatchedTransferLegs(ctx context.Context, store db.Store) error {
+	legs, err := store.ListUnmatchedTransferLegs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(legs) == 0 {
+		return nil
+	}
+
+	transferIDs := make([]int64, len(legs))
+	for i, leg := range legs {
+		transferIDs[i] = leg.TransferID
+	}
+	log.Warn().Ints64("transfer_ids", transferIDs).Msg("transfers have entries without a transfer id, see unmatched_transfer_legs")
+	return nil
 }
 
-func runGatewayServer(config util.Config, store db.Store) {
+func runGrpcServer(ctx context.Context, waitGroup *errgroup.Group, config util.Config, store db.Store, checker *healthChecker) {
 	server, err := gapi.NewServer(config, store)
 	if err != nil {
-		log.Fatal("cannot create server: ", err)
+		log.Fatal().Err(err).Msg("cannot create server")
 	}
 
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			otelgrpc.UnaryServerInterceptor(),
+			gapi.GrpcRequestID,
+			gapi.GrpcLogger,
+			gapi.GrpcMetrics,
+			server.UnaryAuthInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			otelgrpc.StreamServerInterceptor(),
+			gapi.GrpcStreamRequestID,
+			server.StreamAuthInterceptor,
+		),
+	)
+	pb.RegisterBankitaServer(grpcServer, server)
+	healthpb.RegisterHealthServer(grpcServer, checker.grpcHealth)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create listener")
+	}
+
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start GRPC server: %s", listener.Addr().String())
+		err := grpcServer.Serve(listener)
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Error().Err(err).Msg("gRPC server failed to serve")
+			return err
+		}
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		waitForDrain(config.ShutdownDrainDelay)
+		log.Info().Msg("graceful shutdown gRPC server")
+
+		// GracefulStop waits for in-flight RPCs such as TransferTx to finish.
+		// Force the stop once the drain timeout is reached.
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		timer := time.NewTimer(config.ShutdownTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-stopped:
+		case <-timer.C:
+			log.Warn().Msg("gRPC server drain timeout reached, forcing stop")
+			grpcServer.Stop()
+		}
+
+		log.Info().Msg("gRPC server is stopped")
+		return nil
+	})
+}
+
+func runGatewayServer(ctx context.Context, waitGroup *errgroup.Group, config util.Config, checker *healthChecker) {
 	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames: true,
@@ -75,40 +326,131 @@ func runGatewayServer(config util.Config, store db.Store) {
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOption)
+	grpcMux := runtime.NewServeMux(
+		jsonOption,
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(gatewayOutgoingHeaderMatcher),
+		runtime.WithForwardResponseOption(gapi.GatewayForwardResponse),
+		runtime.WithErrorHandler(gapi.GatewayErrorHandler),
+	)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// The connection to the gRPC server outlives ctx so that requests still
+	// being drained by Shutdown can reach the backend.
+	connCtx, closeConn := context.WithCancel(context.Background())
 
-	err = pb.RegisterBankitaHandlerServer(ctx, grpcMux, server)
+	// The gateway proxies to the gRPC server instead of calling it in-process,
+	// so every HTTP request goes through the same interceptor chain.
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
+	}
+	err := pb.RegisterBankitaHandlerFromEndpoint(connCtx, grpcMux, config.GRPCServerAddress, dialOptions)
 	if err != nil {
-		log.Fatal("Cannot register handler server")
+		log.Fatal().Err(err).Msg("cannot register handler server")
 	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.HandleFunc("/healthz", checker.healthz)
+	mux.HandleFunc("/readyz", checker.readyz)
+	mux.Handle("/metrics", promhttp.Handler())
 
-	listener, err := net.Listen("tcp", config.HTTPServerAddress)
-	if err != nil {
-		log.Fatal("cannot create listener: ", err)
+	httpServer := &http.Server{
+		Addr:    config.HTTPServerAddress,
+		Handler: otelhttp.NewHandler(gapi.HttpRequestID(gapi.HttpLogger(mux)), "gateway"),
 	}
 
-	log.Printf("start HTTP Gateway server: %s", listener.Addr().String())
-	err = http.Serve(listener, mux)
+	serveHTTP(ctx, waitGroup, config, httpServer, "HTTP Gateway", closeConn)
+}
 
+func runGinServer(ctx context.Context, waitGroup *errgroup.Group, config util.Config, store db.Store) {
+	server, err := api.NewServer(config, store)
 	if err != nil {
-		log.Fatal("cannot start HTTP Gateway server: ", err)
+		log.Fatal().Err(err).Msg("cannot create server")
+	}
+
+	httpServer := &http.Server{
+		Addr:    config.GinServerAddress,
+		Handler: server.Handler(),
 	}
+
+	serveHTTP(ctx, waitGroup, config, httpServer, "Gin", func() {})
 }
 
-func runGinServer(config util.Config, store db.Store) {
-	server, err := api.NewServer(config, store)
-	if err != nil {
-		log.Fatal("cannot create server: ", err)
+// runMetricsServer serves the Prometheus metrics on a listener of its own, so
+// they can be scraped whichever front-ends run, including gRPC alone.
+func runMetricsServer(ctx context.Context, waitGroup *errgroup.Group, config util.Config) {
+	if config.MetricsServerAddress == "" {
+		log.Warn().Msg("METRICS_SERVER_ADDRESS is not set, metrics are only served by the HTTP front-ends")
+		return
 	}
 
-	err = server.Start(config.HTTPServerAddress)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	httpServer := &http.Server{
+		Addr:    config.MetricsServerAddress,
+		Handler: mux,
+	}
+
+	serveHTTP(ctx, waitGroup, config, httpServer, "metrics", func() {})
+}
+
+// serveHTTP runs httpServer in the wait group and shuts it down once ctx is
+// done and ShutdownDrainDelay has passed, giving in-flight requests up to
+// ShutdownTimeout to complete before calling onStop.
+func serveHTTP(ctx context.Context, waitGroup *errgroup.Group, config util.Config, httpServer *http.Server, name string, onStop func()) {
+	listener, err := net.Listen("tcp", httpServer.Addr)
 	if err != nil {
-		log.Fatal("cannot start server: ", err)
+		log.Fatal().Err(err).Msg("cannot create listener")
+	}
+
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start %s server: %s", name, listener.Addr().String())
+		err := httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msgf("%s server failed to serve", name)
+			return err
+		}
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		waitForDrain(config.ShutdownDrainDelay)
+		log.Info().Msgf("graceful shutdown %s server", name)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		defer cancel()
+
+		err := httpServer.Shutdown(shutdownCtx)
+		onStop()
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to shutdown %s server", name)
+			return err
+		}
+
+		log.Info().Msgf("%s server is stopped", name)
+		return nil
+	})
+}
+
+// gatewayHeaderMatcher forwards the HTTP headers the gRPC service reads as
+// metadata in addition to the ones grpc-gateway forwards by default.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case "idempotency-key", "x-request-id":
+		return strings.ToLower(key), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayOutgoingHeaderMatcher keeps the username the gRPC server reports
+// for the gateway logs out of the HTTP response.
+func gatewayOutgoingHeaderMatcher(key string) (string, bool) {
+	if strings.ToLower(key) == "x-authenticated-user" {
+		return "", false
 	}
+	return fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), true
 }
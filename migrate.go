@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/rs/zerolog/log"
+	"github.com/superjantung/bankita-api/db/migration"
+	"github.com/superjantung/bankita-api/util"
+
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+const migrateUsage = "usage: bankita migrate up [N] | down [N|all] | status | version"
+
+// runDBMigration applies every pending migration.
+func runDBMigration(config util.Config) error {
+	m, src, err := newMigrate(config)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	defer m.Close()
+
+	err = m.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	log.Info().Msg("db migrated successfully")
+	return nil
+}
+
+// runMigrateCommand implements the migrate subcommand. up applies all pending
+// migrations unless a step count is given, down rolls back one migration
+// unless a step count or "all" is given.
+func runMigrateCommand(config util.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	command := args[0]
+	var steps int
+	var err error
+	switch command {
+	case "up":
+		steps, err = parseSteps(args[1:], 0)
+	case "down":
+		steps, err = parseSteps(args[1:], 1)
+	case "status", "version":
+	default:
+		err = errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	m, src, err := newMigrate(config)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	defer m.Close()
+
+	switch command {
+	case "up":
+		if steps == 0 {
+			err = m.Up()
+		} else {
+			err = m.Steps(steps)
+		}
+	case "down":
+		if steps == 0 {
+			err = m.Down()
+		} else {
+			err = m.Steps(-steps)
+		}
+	case "status":
+		return printMigrationStatus(m, src)
+	case "version":
+		return printMigrationVersion(m)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return printMigrationStatus(m, src)
+}
+
+// newMigrate uses the migrations embedded in the binary unless MIGRATION_URL
+// points to another source, such as file://db/migration.
+func newMigrate(config util.Config) (*migrate.Migrate, source.Driver, error) {
+	var src source.Driver
+	var err error
+	if config.MigrationURL != "" {
+		src, err = source.Open(config.MigrationURL)
+	} else {
+		src, err = iofs.New(migration.FS, ".")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open migration source: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("migrations", src, migrationDatabaseURL(config.DBSource))
+	if err != nil {
+		src.Close()
+		return nil, nil, fmt.Errorf("cannot create migrate instance: %w", err)
+	}
+
+	return m, src, nil
+}
+
+// schemaUpToDate reports whether the database is at the latest migration and
+// not left dirty by a failed one.
+func schemaUpToDate(config util.Config) (bool, error) {
+	m, src, err := newMigrate(config)
+	if err != nil {
+		return false, err
+	}
+	defer src.Close()
+	defer m.Close()
+
+	current, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	latest, err := src.First()
+	if err != nil {
+		return false, err
+	}
+	for {
+		next, err := src.Next(latest)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return false, err
+		}
+		latest = next
+	}
+
+	return current == latest && !dirty, nil
+}
+
+func printMigrationStatus(m *migrate.Migrate, src source.Driver) error {
+	current, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	applied := err == nil
+
+	version, err := src.First()
+	for err == nil {
+		state := "pending"
+		if applied && version <= current {
+			state = "applied"
+			if version == current && dirty {
+				state = "dirty"
+			}
+		}
+		fmt.Printf("%06d %s\n", version, state)
+
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func printMigrationVersion(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("no migration applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d (dirty: %t)\n", version, dirty)
+	return nil
+}
+
+func parseSteps(args []string, defaultSteps int) (int, error) {
+	if len(args) == 0 {
+		return defaultSteps, nil
+	}
+	if args[0] == "all" {
+		return 0, nil
+	}
+
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid number of steps %q: %s", args[0], migrateUsage)
+	}
+	return steps, nil
+}
+
+// migrationDatabaseURL points the connection string at the pgx/v5 driver of
+// golang-migrate, which registers itself under the pgx5 scheme.
+func migrationDatabaseURL(dbSource string) string {
+	for _, scheme := range []string{"postgresql://", "postgres://"} {
+		if strings.HasPrefix(dbSource, scheme) {
+			return "pgx5://" + strings.TrimPrefix(dbSource, scheme)
+		}
+	}
+	return dbSource
+}
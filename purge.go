@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	db "github.com/superjantung/bankita-api/db/sqlc"
+	"golang.org/x/sync/errgroup"
+)
+
+// runIdempotencyKeyPurger deletes expired idempotency keys every interval
+// until ctx is done. Expired keys are already ignored when a key is claimed,
+// so the purge only keeps the table from growing.
+func runIdempotencyKeyPurger(ctx context.Context, waitGroup *errgroup.Group, store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	waitGroup.Go(func() error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			count, err := store.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error().Err(err).Msg("cannot purge expired idempotency keys")
+				}
+				continue
+			}
+			log.Info().Int64("count", count).Msg("purged expired idempotency keys")
+		}
+	})
+}
@@ -2,10 +2,8 @@
 
 set -e
 
-# Run database migration
-echo "Running database migration"
-source /app/app.env
-/app/migrate -path /app/migration -database "$DB_SOURCE" -verbose up
+# Database migrations are embedded in the binary and applied on startup
+# when AUTO_MIGRATE is enabled.
 
 # Start the application
 echo "Starting the application"
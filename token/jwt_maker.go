@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 )
 
 const minSecretKeySize = 32
@@ -22,18 +23,32 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	return &JWTMaker{secretKey}, nil
 }
 
-func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *JWTMaker) CreateRefreshToken(username string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, TokenTypeRefreshToken, duration)
 	if err != nil {
 		return "", payload, err
 	}
 
+	return maker.createToken(payload)
+}
+
+func (maker *JWTMaker) CreateAccessToken(username string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, TokenTypeAccessToken, duration)
+	if err != nil {
+		return "", payload, err
+	}
+	payload.SessionID = sessionID
+
+	return maker.createToken(payload)
+}
+
+func (maker *JWTMaker) createToken(payload *Payload) (string, *Payload, error) {
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 	token, err := jwtToken.SignedString([]byte(maker.secretKey))
 	return token, payload, err
 }
 
-func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
+func (maker *JWTMaker) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
 	keyFunc := func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
@@ -53,7 +68,7 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 	}
 
 	payload, ok := jwtToken.Claims.(*Payload)
-	if !ok {
+	if !ok || payload.Type != tokenType {
 		return nil, ErrInvalidToken
 	}
 
@@ -1,8 +1,20 @@
 package token
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Maker interface {
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
-	VerifyToken(token string) (*Payload, error)
+	// CreateRefreshToken creates a token that can only be exchanged for new
+	// access tokens. Its ID identifies the session it starts.
+	CreateRefreshToken(username string, duration time.Duration) (string, *Payload, error)
+	// CreateAccessToken creates a token bound to the session of the refresh
+	// token it was issued with, so that it stops working once the session
+	// is blocked.
+	CreateAccessToken(username string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error)
+	// VerifyToken checks the token and fails with ErrInvalidToken unless it
+	// is of tokenType.
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/aead/chacha20poly1305"
+	"github.com/google/uuid"
 	"github.com/o1egl/paseto"
 )
 
@@ -26,17 +27,31 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateRefreshToken(username string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, TokenTypeRefreshToken, duration)
 	if err != nil {
 		return "", payload, err
 	}
 
+	return maker.createToken(payload)
+}
+
+func (maker *PasetoMaker) CreateAccessToken(username string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, TokenTypeAccessToken, duration)
+	if err != nil {
+		return "", payload, err
+	}
+	payload.SessionID = sessionID
+
+	return maker.createToken(payload)
+}
+
+func (maker *PasetoMaker) createToken(payload *Payload) (string, *Payload, error) {
 	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
 	return token, payload, err
 }
 
-func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+func (maker *PasetoMaker) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
 	payload := &Payload{}
 
 	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
@@ -44,6 +59,10 @@ func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 		return nil, ErrInvalidToken
 	}
 
+	if payload.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+
 	err = payload.Valid()
 	if err != nil {
 		return nil, err
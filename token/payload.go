@@ -12,14 +12,25 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// TokenType tells access tokens, which authorize API calls, from refresh
+// tokens, which can only be exchanged for new access tokens.
+type TokenType byte
+
+const (
+	TokenTypeAccessToken  TokenType = 1
+	TokenTypeRefreshToken TokenType = 2
+)
+
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
+	Type      TokenType `json:"token_type"`
 	Username  string    `json:"username"`
+	SessionID uuid.UUID `json:"session_id"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -27,6 +38,7 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 
 	payload := &Payload{
 		ID:        tokenID,
+		Type:      tokenType,
 		Username:  username,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
@@ -7,13 +7,37 @@ import (
 )
 
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	Environment                 string        `mapstructure:"ENVIRONMENT"`
+	LogLevel                    string        `mapstructure:"LOG_LEVEL"`
+	DBSource                    string        `mapstructure:"DB_SOURCE"`
+	DBMaxConns                  int32         `mapstructure:"DB_MAX_CONNS"`
+	DBMinConns                  int32         `mapstructure:"DB_MIN_CONNS"`
+	DBMaxConnLifetime           time.Duration `mapstructure:"DB_MAX_CONN_LIFETIME"`
+	DBMaxConnIdleTime           time.Duration `mapstructure:"DB_MAX_CONN_IDLE_TIME"`
+	DBHealthCheckPeriod         time.Duration `mapstructure:"DB_HEALTH_CHECK_PERIOD"`
+	DBConnectTimeout            time.Duration `mapstructure:"DB_CONNECT_TIMEOUT"`
+	MigrationURL                string        `mapstructure:"MIGRATION_URL"`
+	AutoMigrate                 bool          `mapstructure:"AUTO_MIGRATE"`
+	HTTPServerAddress           string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GRPCServerAddress           string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	GinServerAddress            string        `mapstructure:"GIN_SERVER_ADDRESS"`
+	MetricsServerAddress        string        `mapstructure:"METRICS_SERVER_ADDRESS"`
+	ServerModes                 string        `mapstructure:"SERVER_MODES"`
+	TrustedProxies              string        `mapstructure:"TRUSTED_PROXIES"`
+	ShutdownTimeout             time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ShutdownDrainDelay          time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+	TracingExporter             string        `mapstructure:"TRACING_EXPORTER"`
+	TracingSampleRatio          float64       `mapstructure:"TRACING_SAMPLE_RATIO"`
+	OTLPEndpoint                string        `mapstructure:"OTLP_ENDPOINT"`
+	OTLPInsecure                bool          `mapstructure:"OTLP_INSECURE"`
+	TokenSymmetricKey           string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration         time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration        time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	IdempotencyKeyTTL           time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencyKeyPurgeInterval time.Duration `mapstructure:"IDEMPOTENCY_KEY_PURGE_INTERVAL"`
+	FXRatesFile                 string        `mapstructure:"FX_RATES_FILE"`
+	FXSpread                    string        `mapstructure:"FX_SPREAD"`
+	FXQuoteTTL                  time.Duration `mapstructure:"FX_QUOTE_TTL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -1,14 +1,108 @@
 package util
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
 const (
 	USD = "USD"
 	IDR = "IDR"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, IDR:
-		return true
+// Currency describes an ISO 4217 currency. Amounts are stored as integers in
+// minor units, MinorUnit being the number of decimal places.
+type Currency struct {
+	Code      string `json:"code"`
+	MinorUnit int    `json:"minor_unit"`
+	Symbol    string `json:"symbol"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// FormatAmount formats an amount in minor units as a decimal string, e.g.
+// 1250 as "12.50" when MinorUnit is 2.
+func (currency Currency) FormatAmount(amount int64) string {
+	sign, magnitude := "", uint64(amount)
+	if amount < 0 {
+		// Negate in uint64 so that math.MinInt64 does not overflow.
+		sign, magnitude = "-", uint64(-(amount+1))+1
+	}
+	digits := strconv.FormatUint(magnitude, 10)
+	if currency.MinorUnit <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= currency.MinorUnit {
+		digits = strings.Repeat("0", currency.MinorUnit-len(digits)+1) + digits
+	}
+	point := len(digits) - currency.MinorUnit
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// CurrencyRegistry holds the known currencies in memory. It is safe for
+// concurrent use.
+type CurrencyRegistry struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewCurrencyRegistry(currencies []Currency) *CurrencyRegistry {
+	registry := &CurrencyRegistry{}
+	registry.Load(currencies)
+	return registry
+}
+
+// Load replaces the registered currencies.
+func (registry *CurrencyRegistry) Load(currencies []Currency) {
+	byCode := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
 	}
-	return false
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.currencies = byCode
+}
+
+// Get returns the currency with the given code, enabled or not.
+func (registry *CurrencyRegistry) Get(code string) (Currency, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	currency, ok := registry.currencies[code]
+	return currency, ok
+}
+
+// IsSupported reports whether the currency is registered and enabled.
+func (registry *CurrencyRegistry) IsSupported(code string) bool {
+	currency, ok := registry.Get(code)
+	return ok && currency.Enabled
+}
+
+// List returns the registered currencies ordered by code.
+func (registry *CurrencyRegistry) List() []Currency {
+	registry.mu.RLock()
+	currencies := make([]Currency, 0, len(registry.currencies))
+	for _, currency := range registry.currencies {
+		currencies = append(currencies, currency)
+	}
+	registry.mu.RUnlock()
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+	return currencies
+}
+
+// Currencies is the registry used to validate requests. It starts with the
+// built-in currencies and is replaced by the currencies table at startup.
+var Currencies = NewCurrencyRegistry([]Currency{
+	{Code: USD, MinorUnit: 2, Symbol: "$", Enabled: true},
+	{Code: IDR, MinorUnit: 2, Symbol: "Rp", Enabled: true},
+})
+
+func IsSupportedCurrency(currency string) bool {
+	return Currencies.IsSupported(currency)
 }
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	registry := NewCurrencyRegistry([]Currency{
+		{Code: "SGD", MinorUnit: 2, Symbol: "S$", Enabled: true},
+		{Code: "EUR", MinorUnit: 2, Symbol: "€", Enabled: false},
+	})
+
+	require.True(t, registry.IsSupported("SGD"))
+	require.False(t, registry.IsSupported("EUR"))
+	require.False(t, registry.IsSupported(USD))
+
+	currency, ok := registry.Get("EUR")
+	require.True(t, ok)
+	require.Equal(t, "€", currency.Symbol)
+
+	currencies := registry.List()
+	require.Len(t, currencies, 2)
+	require.Equal(t, "EUR", currencies[0].Code)
+
+	registry.Load([]Currency{{Code: USD, MinorUnit: 2, Enabled: true}})
+	require.True(t, registry.IsSupported(USD))
+	require.False(t, registry.IsSupported("SGD"))
+}
+
+func TestFormatAmount(t *testing.T) {
+	cents := Currency{Code: USD, MinorUnit: 2}
+	require.Equal(t, "12.50", cents.FormatAmount(1250))
+	require.Equal(t, "0.05", cents.FormatAmount(5))
+	require.Equal(t, "0.00", cents.FormatAmount(0))
+	require.Equal(t, "-1.00", cents.FormatAmount(-100))
+	require.Equal(t, "-92233720368547758.08", cents.FormatAmount(math.MinInt64))
+
+	whole := Currency{Code: IDR, MinorUnit: 0}
+	require.Equal(t, "1250", whole.FormatAmount(1250))
+	require.Equal(t, "-7", whole.FormatAmount(-7))
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// SetupLogger configures the global logger: human readable console output in
+// development and JSON everywhere else.
+func SetupLogger(environment string, level string) error {
+	logLevel := zerolog.InfoLevel
+	if level != "" {
+		var err error
+		logLevel, err = zerolog.ParseLevel(strings.ToLower(level))
+		if err != nil {
+			return err
+		}
+	}
+	zerolog.SetGlobalLevel(logLevel)
+
+	if environment == "development" {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	}
+
+	// Code that logs with log.Ctx falls back to the global logger when the
+	// context carries no request logger.
+	zerolog.DefaultContextLogger = &log.Logger
+	return nil
+}
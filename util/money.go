@@ -0,0 +1,142 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrAmountOverflow   = errors.New("amount overflows")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Money is an amount in the minor units of its currency, e.g. 1250 USD is
+// $12.50. Arithmetic only combines amounts of the same currency and fails
+// instead of overflowing.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount in major units, such as "12.50", using
+// the minor unit of the currency from Currencies.
+func ParseMoney(amount string, currency string) (Money, error) {
+	c, ok := Currencies.Get(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency: %s", currency)
+	}
+
+	digits := strings.TrimPrefix(amount, "-")
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if len(fraction) > c.MinorUnit {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places for %s", ErrInvalidAmount, amount, c.MinorUnit, currency)
+	}
+
+	fraction += strings.Repeat("0", c.MinorUnit-len(fraction))
+	minor, err := strconv.ParseInt(amount[:len(amount)-len(digits)]+whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrAmountOverflow, amount)
+	}
+
+	return NewMoney(minor, currency), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns money + other.
+func (money Money) Add(other Money) (Money, error) {
+	if money.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, money.Currency, other.Currency)
+	}
+	if (other.Amount > 0 && money.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && money.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return NewMoney(money.Amount+other.Amount, money.Currency), nil
+}
+
+// Sub returns money - other.
+func (money Money) Sub(other Money) (Money, error) {
+	if money.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, money.Currency, other.Currency)
+	}
+	if (other.Amount < 0 && money.Amount > math.MaxInt64+other.Amount) ||
+		(other.Amount > 0 && money.Amount < math.MinInt64+other.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return NewMoney(money.Amount-other.Amount, money.Currency), nil
+}
+
+// Cmp returns -1, 0 or +1 depending on whether money is less than, equal to
+// or greater than other.
+func (money Money) Cmp(other Money) (int, error) {
+	if money.Currency != other.Currency {
+		return 0, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, money.Currency, other.Currency)
+	}
+	switch {
+	case money.Amount < other.Amount:
+		return -1, nil
+	case money.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (money Money) IsPositive() bool {
+	return money.Amount > 0
+}
+
+// Decimal formats the amount in major units, e.g. "12.50". Amounts in a
+// currency missing from Currencies are formatted as whole minor units.
+func (money Money) Decimal() string {
+	currency, _ := Currencies.Get(money.Currency)
+	return currency.FormatAmount(money.Amount)
+}
+
+func (money Money) String() string {
+	return money.Decimal() + " " + money.Currency
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes money as {"amount": "12.50", "currency": "USD"}, the
+// amount being a decimal string so that clients never lose precision.
+func (money Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: money.Decimal(), Currency: money.Currency})
+}
+
+func (money *Money) UnmarshalJSON(data []byte) error {
+	var value moneyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := ParseMoney(value.Amount, value.Currency)
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		amount   string
+		currency string
+		expected int64
+		err      error
+	}{
+		{amount: "12.50", currency: USD, expected: 1250},
+		{amount: "12.5", currency: USD, expected: 1250},
+		{amount: "12", currency: USD, expected: 1200},
+		{amount: "0.01", currency: USD, expected: 1},
+		{amount: "-3.10", currency: IDR, expected: -310},
+		{amount: "92233720368547758.07", currency: USD, expected: math.MaxInt64},
+		{amount: "92233720368547758.08", currency: USD, err: ErrAmountOverflow},
+		{amount: "12.505", currency: USD, err: ErrInvalidAmount},
+		{amount: "12.", currency: USD, err: ErrInvalidAmount},
+		{amount: ".5", currency: USD, err: ErrInvalidAmount},
+		{amount: "+1", currency: USD, err: ErrInvalidAmount},
+		{amount: "1e3", currency: USD, err: ErrInvalidAmount},
+		{amount: "", currency: USD, err: ErrInvalidAmount},
+	}
+
+	for _, tc := range testCases {
+		money, err := ParseMoney(tc.amount, tc.currency)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.amount)
+			continue
+		}
+		require.NoError(t, err, tc.amount)
+		require.Equal(t, NewMoney(tc.expected, tc.currency), money)
+	}
+
+	_, err := ParseMoney("1.00", "XYZ")
+	require.Error(t, err)
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := NewMoney(1250, USD)
+	b := NewMoney(250, USD)
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(1500, USD), sum)
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(-1000, USD), diff)
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	require.Equal(t, 1, cmp)
+
+	_, err = a.Add(NewMoney(1, IDR))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = a.Cmp(NewMoney(1, IDR))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = NewMoney(math.MaxInt64, USD).Add(NewMoney(1, USD))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = NewMoney(math.MinInt64, USD).Sub(NewMoney(1, USD))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = NewMoney(0, USD).Sub(NewMoney(math.MinInt64, USD))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(NewMoney(1250, USD))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount": "12.50", "currency": "USD"}`, string(data))
+
+	var money Money
+	err = json.Unmarshal([]byte(`{"amount": "0.05", "currency": "IDR"}`), &money)
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(5, IDR), money)
+	require.Equal(t, "0.05 IDR", money.String())
+
+	err = json.Unmarshal([]byte(`{"amount": "0.005", "currency": "IDR"}`), &money)
+	require.ErrorIs(t, err, ErrInvalidAmount)
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader       = "X-Request-ID"
+	maxRequestIDLength    = 128
+	minRequestIDCharacter = '!'
+	maxRequestIDCharacter = '~'
+)
+
+// RequestID returns the request ID supplied by the client when it is a
+// short, printable ASCII string, and a newly generated one otherwise.
+func RequestID(candidate string) string {
+	if candidate == "" || len(candidate) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+
+	for i := 0; i < len(candidate); i++ {
+		if candidate[i] < minRequestIDCharacter || candidate[i] > maxRequestIDCharacter {
+			return uuid.NewString()
+		}
+	}
+	return candidate
+}
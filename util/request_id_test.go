@@ -0,0 +1,21 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	require.Equal(t, "abc-123", RequestID("abc-123"))
+
+	for _, candidate := range []string{"", "has space", "line\nbreak", strings.Repeat("a", 129)} {
+		requestID := RequestID(candidate)
+		require.NotEqual(t, candidate, requestID)
+
+		_, err := uuid.Parse(requestID)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,8 @@
+package util
+
+// Roles a user can have. Depositors own accounts; operators move cash in
+// and out of them.
+const (
+	DepositorRole = "depositor"
+	OperatorRole  = "operator"
+)
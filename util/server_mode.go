@@ -0,0 +1,72 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	GinServer     = "gin"
+	GRPCServer    = "grpc"
+	GatewayServer = "gateway"
+	AllServers    = "all"
+)
+
+// ServerModes is the set of front-ends the process runs.
+type ServerModes map[string]bool
+
+// ParseServerModes parses a comma separated list of front-ends such as
+// "grpc,gateway". "all" enables every front-end.
+func ParseServerModes(value string) (ServerModes, error) {
+	modes := ServerModes{}
+	for _, mode := range strings.Split(value, ",") {
+		mode = strings.ToLower(strings.TrimSpace(mode))
+		switch mode {
+		case GinServer, GRPCServer, GatewayServer:
+			modes[mode] = true
+		case AllServers:
+			modes[GinServer] = true
+			modes[GRPCServer] = true
+			modes[GatewayServer] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("unsupported server mode: %s", mode)
+		}
+	}
+
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("no server mode selected")
+	}
+	return modes, nil
+}
+
+// ValidateServerAddresses makes sure the enabled front-ends and the metrics
+// server do not share a listen address.
+func (config Config) ValidateServerAddresses(modes ServerModes) error {
+	addresses := map[string]string{
+		GinServer:     config.GinServerAddress,
+		GRPCServer:    config.GRPCServerAddress,
+		GatewayServer: config.HTTPServerAddress,
+	}
+
+	used := map[string]string{}
+	for _, mode := range []string{GinServer, GRPCServer, GatewayServer} {
+		if !modes[mode] {
+			continue
+		}
+
+		address := addresses[mode]
+		if address == "" {
+			return fmt.Errorf("%s server is enabled but has no address", mode)
+		}
+		if other, ok := used[address]; ok {
+			return fmt.Errorf("%s and %s servers cannot both listen on %s", other, mode, address)
+		}
+		used[address] = mode
+	}
+
+	if other, ok := used[config.MetricsServerAddress]; ok {
+		return fmt.Errorf("%s and metrics servers cannot both listen on %s", other, config.MetricsServerAddress)
+	}
+	return nil
+}
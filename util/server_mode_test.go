@@ -0,0 +1,49 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerModes(t *testing.T) {
+	modes, err := ParseServerModes("grpc, Gateway")
+	require.NoError(t, err)
+	require.Equal(t, ServerModes{GRPCServer: true, GatewayServer: true}, modes)
+
+	modes, err = ParseServerModes("all")
+	require.NoError(t, err)
+	require.Equal(t, ServerModes{GinServer: true, GRPCServer: true, GatewayServer: true}, modes)
+
+	_, err = ParseServerModes("grpc,rest")
+	require.Error(t, err)
+
+	_, err = ParseServerModes("")
+	require.Error(t, err)
+}
+
+func TestValidateServerAddresses(t *testing.T) {
+	config := Config{
+		HTTPServerAddress: "0.0.0.0:8080",
+		GRPCServerAddress: "0.0.0.0:9090",
+		GinServerAddress:  "0.0.0.0:8080",
+	}
+
+	err := config.ValidateServerAddresses(ServerModes{GRPCServer: true, GatewayServer: true})
+	require.NoError(t, err)
+
+	err = config.ValidateServerAddresses(ServerModes{GinServer: true, GatewayServer: true})
+	require.Error(t, err)
+
+	config.GinServerAddress = ""
+	err = config.ValidateServerAddresses(ServerModes{GinServer: true})
+	require.Error(t, err)
+
+	config.MetricsServerAddress = "0.0.0.0:9100"
+	err = config.ValidateServerAddresses(ServerModes{GRPCServer: true})
+	require.NoError(t, err)
+
+	config.MetricsServerAddress = config.GRPCServerAddress
+	err = config.ValidateServerAddresses(ServerModes{GRPCServer: true})
+	require.Error(t, err)
+}
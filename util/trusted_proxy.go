@@ -0,0 +1,55 @@
+package util
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies is the set of networks whose forwarded client address
+// headers are believed.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a comma separated list of IP addresses and CIDR
+// ranges such as "127.0.0.1,10.0.0.0/8". An empty list trusts no proxy.
+func ParseTrustedProxies(value string) (TrustedProxies, error) {
+	proxies := TrustedProxies{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s: %w", entry, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s: %w", entry, err)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// Contains reports whether ip belongs to one of the trusted networks.
+func (proxies TrustedProxies) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies("127.0.0.1, ::1, 10.0.0.0/8")
+	require.NoError(t, err)
+	require.Len(t, proxies, 3)
+
+	require.True(t, proxies.Contains("127.0.0.1"))
+	require.True(t, proxies.Contains("::1"))
+	require.True(t, proxies.Contains("10.1.2.3"))
+	require.True(t, proxies.Contains("::ffff:10.1.2.3"))
+	require.False(t, proxies.Contains("127.0.0.2"))
+	require.False(t, proxies.Contains("203.0.113.5"))
+	require.False(t, proxies.Contains("not-an-ip"))
+
+	proxies, err = ParseTrustedProxies("")
+	require.NoError(t, err)
+	require.False(t, proxies.Contains("127.0.0.1"))
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	require.Error(t, err)
+
+	_, err = ParseTrustedProxies("localhost")
+	require.Error(t, err)
+}